@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
@@ -25,3 +27,13 @@ func (a *App) startup(ctx context.Context) {
 func (a *App) Greet(name string) string {
 	return fmt.Sprintf("Hello %s, It's show time!", name)
 }
+
+// logError writes err to the Wails log, which GUI builds keep, or to stderr
+// before the app has started.
+func (a *App) logError(err error) {
+	if a.ctx == nil {
+		println("Error:", err.Error())
+		return
+	}
+	runtime.LogError(a.ctx, err.Error())
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidArchive is returned by ImportArchive for archives that were not
+// written by ExportArchive or that try to write outside the data directory.
+var ErrInvalidArchive = errors.New("invalid save archive")
+
+// ExportArchive writes saveName together with its sidecars, backups, deltas
+// and bundle to destPath as a .tar.gz archive, for moving a save to another
+// machine with ImportArchive.
+func (s *SaveManager) ExportArchive(saveName string, destPath string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	mu := s.saveLock(saveName)
+	// Taken for writing so that an interrupted SaveBundle can be finished
+	// rather than exported half done.
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := s.savePath(saveName); err != nil {
+		return fmt.Errorf("export archive %q: %w", saveName, err)
+	}
+	if err := s.recoverBundle(s.bundleDir(saveName)); err != nil {
+		return fmt.Errorf("export archive %q: %w", saveName, err)
+	}
+	var files []string
+	for _, ext := range append(slices.Clone(s.fileExts), sidecarExts...) {
+		files = append(files, saveName+ext)
+	}
+	for _, root := range []string{"backups", "bundles", "deltas"} {
+		entries, err := s.fs.ReadDir(filepath.Join(s.dir(), root, saveName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("export archive %q: %w", saveName, err)
+		}
+		for _, file := range entries {
+			if !file.IsDir() {
+				files = append(files, path.Join(root, saveName, file.Name()))
+			}
+		}
+	}
+
+	err := s.writeFileAtomic(destPath, s.fileMode, func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		tw := tar.NewWriter(zw)
+		for _, name := range files {
+			data, err := readFile(s.fs, filepath.Join(s.dir(), filepath.FromSlash(name)))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: s.now()}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("export archive %q: %w", saveName, err)
+	}
+	return nil
+}
+
+// ImportArchive unpacks an archive written by ExportArchive into the data
+// directory and returns the name of the imported save. It refuses to replace
+// an existing save, and rejects archives with entries that would land
+// anywhere but the save's own files.
+func (s *SaveManager) ImportArchive(srcPath string) (string, error) {
+	saveName, files, err := s.readArchive(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("import archive %s: %w", srcPath, err)
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := s.savePath(saveName); err == nil {
+		return "", fmt.Errorf("import archive %s: save %q: %w", srcPath, saveName, ErrSaveExists)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if s.maxSaves > 0 && countsTowardLimit(saveName) {
+		s.createMu.Lock()
+		defer s.createMu.Unlock()
+		if err := s.checkSaveLimit(saveName); err != nil {
+			return "", err
+		}
+	}
+
+	var main string
+	for name := range files {
+		if _, ok := s.trimSaveExt(name); ok && !strings.Contains(name, "/") {
+			main = name
+			continue
+		}
+		if err := s.writeArchiveFile(name, files[name]); err != nil {
+			return "", fmt.Errorf("import archive %s: %w", srcPath, err)
+		}
+	}
+	// Write the save itself last, so it only appears once it is complete.
+	if err := s.writeArchiveFile(main, files[main]); err != nil {
+		return "", fmt.Errorf("import archive %s: %w", srcPath, err)
+	}
+	return saveName, nil
+}
+
+// readArchive reads the archive at srcPath into memory, keyed by entry name,
+// and returns the save it holds. Every entry must be one of that save's files,
+// and there must be exactly one save file.
+func (s *SaveManager) readArchive(srcPath string) (string, map[string][]byte, error) {
+	f, err := s.fs.Open(srcPath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	tr := tar.NewReader(zr)
+	var saveName string
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return "", nil, fmt.Errorf("%w: %s is not a regular file", ErrInvalidArchive, hdr.Name)
+		}
+		owner, ok := s.archiveEntrySave(hdr.Name)
+		if !ok || (saveName != "" && owner != saveName) {
+			return "", nil, fmt.Errorf("%w: unexpected entry %s", ErrInvalidArchive, hdr.Name)
+		}
+		saveName = owner
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		files[hdr.Name] = data
+	}
+	var variants []string
+	for name := range files {
+		if n, ok := s.trimSaveExt(name); ok && n == saveName {
+			variants = append(variants, name)
+		}
+	}
+	switch len(variants) {
+	case 0:
+		return "", nil, fmt.Errorf("%w: no save in archive", ErrInvalidArchive)
+	case 1:
+		return saveName, files, nil
+	}
+	slices.Sort(variants)
+	return "", nil, fmt.Errorf("%w: several variants of save %q: %s", ErrInvalidArchive, saveName, strings.Join(variants, ", "))
+}
+
+// archiveEntrySave returns the save an archive entry belongs to, reporting
+// false for entries that are not a save file, a sidecar, a backup, a delta
+// or a bundle part, which includes any that could escape the data directory.
+func (s *SaveManager) archiveEntrySave(name string) (string, bool) {
+	if !filepath.IsLocal(name) || path.Clean(name) != name {
+		return "", false
+	}
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 1:
+		saveName, ok := s.trimSaveExt(name)
+		if !ok {
+			saveName, ok = trimSidecarExt(name)
+		}
+		if !ok || validateSaveName(saveName) != nil {
+			return "", false
+		}
+		return saveName, true
+	case len(parts) == 3 && (parts[0] == "backups" || parts[0] == "bundles" || parts[0] == "deltas"):
+		if validateSaveName(parts[1]) != nil || validateSaveName(parts[2]) != nil {
+			return "", false
+		}
+		return parts[1], true
+	}
+	return "", false
+}
+
+func (s *SaveManager) writeArchiveFile(name string, data []byte) error {
+	filename := filepath.Join(s.dir(), filepath.FromSlash(name))
+	if err := s.fs.MkdirAll(filepath.Dir(filename), s.dirMode); err != nil {
+		return err
+	}
+	return s.writeFileAtomic(filename, s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
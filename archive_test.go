@@ -0,0 +1,107 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestArchive writes a .tar.gz holding files, by entry name, to a new
+// temporary file and returns its path.
+func writeTestArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "save.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := gzip.NewWriter(f)
+	tw := tar.NewWriter(zw)
+	for name, data := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src, _ := newTestManager(t)
+	if err := src.SaveGameWithThumbnail("farm", `{"day":1}`, []byte("png data")); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, src, "farm", `{"day":2}`)
+	mustSave(t, src, "other", `{}`)
+	archive := filepath.Join(t.TempDir(), "farm.tar.gz")
+	if err := src.ExportArchive("farm", archive); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, _ := newTestManager(t)
+	saveName, err := dst.ImportArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saveName != "farm" {
+		t.Errorf("ImportArchive = %q, want farm", saveName)
+	}
+	if got := mustLoad(t, dst, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+	if thumbnail, err := dst.GetThumbnail("farm"); err != nil || string(thumbnail) != "png data" {
+		t.Errorf("GetThumbnail = %q, %v", thumbnail, err)
+	}
+	if ids, err := dst.ListBackups("farm"); err != nil || len(ids) != 1 {
+		t.Errorf("ListBackups = %v, %v, want the backup imported", ids, err)
+	}
+	if names, err := dst.GetAllSaves(); err != nil || len(names) != 1 {
+		t.Errorf("GetAllSaves = %v, %v, want only the exported save", names, err)
+	}
+	if _, err := dst.ImportArchive(archive); !errors.Is(err, ErrSaveExists) {
+		t.Errorf("second ImportArchive = %v, want ErrSaveExists", err)
+	}
+}
+
+func TestImportArchiveRejectsPathTraversal(t *testing.T) {
+	for _, name := range []string{
+		"../evil.json",
+		"/abs.json",
+		"backups/../../evil.json",
+		"backups/farm/../../../evil.json",
+		"sub/dir/evil.json",
+	} {
+		archive := writeTestArchive(t, map[string]string{name: `{}`})
+		s, dir := newTestManager(t)
+		if _, err := s.ImportArchive(archive); !errors.Is(err, ErrInvalidArchive) {
+			t.Errorf("ImportArchive with entry %s = %v, want ErrInvalidArchive", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil.json")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("entry %s written outside the data directory", name)
+		}
+	}
+}
+
+func TestImportArchiveRejectsSeveralVariants(t *testing.T) {
+	archive := writeTestArchive(t, map[string]string{
+		"farm.json":    `{"day":1}`,
+		"farm.json.gz": "not really gzip",
+	})
+	s, _ := newTestManager(t)
+	if _, err := s.ImportArchive(archive); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("ImportArchive = %v, want ErrInvalidArchive", err)
+	}
+}
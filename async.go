@@ -0,0 +1,33 @@
+package main
+
+// SaveGameAsync is SaveGame run on a background goroutine, so a large save
+// does not hold up the caller. The result is sent once on the returned
+// channel, which is then closed. Async saves to the same slot are written in
+// the order they were made, so the last one called always wins.
+func (s *SaveManager) SaveGameAsync(saveName, saveData string) <-chan error {
+	result := make(chan error, 1)
+	done := make(chan struct{})
+	s.asyncMu.Lock()
+	prev := s.asyncTail[saveName]
+	if s.asyncTail == nil {
+		s.asyncTail = make(map[string]chan struct{})
+	}
+	s.asyncTail[saveName] = done
+	s.asyncMu.Unlock()
+
+	go func() {
+		if prev != nil {
+			<-prev
+		}
+		result <- s.SaveGame(saveName, saveData)
+		close(result)
+
+		s.asyncMu.Lock()
+		if s.asyncTail[saveName] == done {
+			delete(s.asyncTail, saveName)
+		}
+		s.asyncMu.Unlock()
+		close(done)
+	}()
+	return result
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSaveGameAsync(t *testing.T) {
+	s, _ := newTestManager(t)
+	done := s.SaveGameAsync("farm", `{"day":1}`)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-done; ok {
+		t.Error("SaveGameAsync sent more than one result")
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+	if err := <-s.SaveGameAsync("../farm", `{}`); !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("SaveGameAsync with a bad name = %v, want ErrInvalidSaveName", err)
+	}
+}
+
+func TestSaveGameAsyncLastCallWins(t *testing.T) {
+	s, _ := newTestManager(t)
+	var results []<-chan error
+	for day := 1; day <= 20; day++ {
+		results = append(results, s.SaveGameAsync("farm", fmt.Sprintf(`{"day":%d}`, day)))
+	}
+	for _, done := range results {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":20}` {
+		t.Errorf("LoadGame = %s, want the last async save", got)
+	}
+}
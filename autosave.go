@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// autosaveSuffix names the slot autosaves are written to, so they never
+// overwrite the player's own save.
+const autosaveSuffix = "_autosave"
+
+// StartAutosave writes snapshot() to saveName+"_autosave" every interval
+// until the returned stop function is called. With WithAutosaveRetention
+// each autosave goes to a new slot instead; see AutosaveRetention. The stop
+// function waits for any save in progress to finish and is safe to call more
+// than once. Failed autosaves go to the handler set with WithErrorHandler.
+func (s *SaveManager) StartAutosave(saveName string, interval time.Duration, snapshot func() string) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.writeAutosave(saveName, snapshot()); err != nil {
+					s.reportError(fmt.Errorf("autosave %q: %w", saveName, err))
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// AutosaveRetention decides which autosaves are kept when each autosave is
+// written to a new slot, saveName+"_autosave_1", "_autosave_2" and so on.
+// An autosave survives if any rule keeps it.
+type AutosaveRetention struct {
+	// Last keeps the most recent autosaves.
+	Last int `json:"last"`
+	// Hourly keeps the latest autosave of each of the most recent hours
+	// that have one.
+	Hourly int `json:"hourly"`
+	// Daily keeps the latest autosave of each of the most recent days that
+	// have one.
+	Daily int `json:"daily"`
+}
+
+func (r AutosaveRetention) enabled() bool {
+	return r.Last > 0 || r.Hourly > 0 || r.Daily > 0
+}
+
+// WithAutosaveRetention makes StartAutosave rotate through numbered slots
+// and prune them by r, rather than overwrite a single autosave slot.
+func WithAutosaveRetention(r AutosaveRetention) Option {
+	return func(s *SaveManager) {
+		s.autosaveRetention = r
+	}
+}
+
+// GetAutosaves returns the autosaves of saveName, most recent first.
+func (s *SaveManager) GetAutosaves(saveName string) ([]SaveInfo, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return nil, err
+	}
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		return nil, err
+	}
+	autosaves := []SaveInfo{}
+	for _, info := range infos {
+		if _, ok := autosaveIndex(saveName, info.Name); ok || info.Name == saveName+autosaveSuffix {
+			autosaves = append(autosaves, info)
+		}
+	}
+	return autosaves, nil
+}
+
+// writeAutosave writes saveData as the next autosave of saveName and prunes
+// the older ones.
+func (s *SaveManager) writeAutosave(saveName, saveData string) error {
+	if !s.autosaveRetention.enabled() {
+		return s.SaveGame(saveName+autosaveSuffix, saveData)
+	}
+	slots, err := s.autosaveSlots(saveName)
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(slots) > 0 {
+		next = slots[len(slots)-1].index + 1
+	}
+	slot := autosaveSlot(saveName, next)
+	if err := s.SaveGame(slot, saveData); err != nil {
+		return err
+	}
+	// Retention goes by modification time, so take it from the clock.
+	if err := s.TouchSave(slot); err != nil {
+		return err
+	}
+	return s.pruneAutosaves(saveName)
+}
+
+// pruneAutosaves deletes the numbered autosaves of saveName that no rule of
+// the retention policy keeps.
+func (s *SaveManager) pruneAutosaves(saveName string) error {
+	slots, err := s.autosaveSlots(saveName)
+	if err != nil {
+		return err
+	}
+	// Newest first, so each rule keeps the latest autosave of a period.
+	slices.Reverse(slots)
+	keep := make(map[int]bool)
+	for i := 0; i < min(s.autosaveRetention.Last, len(slots)); i++ {
+		keep[slots[i].index] = true
+	}
+	keepPeriods := func(n int, period func(time.Time) string) {
+		seen := make(map[string]bool)
+		for _, slot := range slots {
+			if len(seen) >= n {
+				return
+			}
+			if p := period(slot.modTime); !seen[p] {
+				seen[p] = true
+				keep[slot.index] = true
+			}
+		}
+	}
+	keepPeriods(s.autosaveRetention.Hourly, func(t time.Time) string {
+		return t.Local().Format("2006-01-02 15")
+	})
+	keepPeriods(s.autosaveRetention.Daily, func(t time.Time) string {
+		return t.Local().Format("2006-01-02")
+	})
+	for _, slot := range slots {
+		if keep[slot.index] {
+			continue
+		}
+		if err := s.DeleteSave(autosaveSlot(saveName, slot.index)); err != nil && !errors.Is(err, ErrSaveNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+type autosaveSlotInfo struct {
+	index   int
+	modTime time.Time
+}
+
+// autosaveSlots returns the numbered autosaves of saveName in the order they
+// were written.
+func (s *SaveManager) autosaveSlots(saveName string) ([]autosaveSlotInfo, error) {
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		return nil, err
+	}
+	var slots []autosaveSlotInfo
+	for _, info := range infos {
+		if i, ok := autosaveIndex(saveName, info.Name); ok {
+			slots = append(slots, autosaveSlotInfo{index: i, modTime: info.ModTime})
+		}
+	}
+	slices.SortFunc(slots, func(a, b autosaveSlotInfo) int {
+		return a.index - b.index
+	})
+	return slots, nil
+}
+
+func autosaveSlot(saveName string, index int) string {
+	return fmt.Sprintf("%s%s_%d", saveName, autosaveSuffix, index)
+}
+
+// autosaveIndex returns the number of the autosave slot name of saveName,
+// reporting false if name is not one.
+func autosaveIndex(saveName, name string) (int, bool) {
+	rest, ok := strings.CutPrefix(name, saveName+autosaveSuffix+"_")
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(rest)
+	return i, err == nil && i > 0 && strconv.Itoa(i) == rest
+}
+
+// isAutosave reports whether saveName is an autosave slot of some save.
+func isAutosave(saveName string) bool {
+	if strings.HasSuffix(saveName, autosaveSuffix) {
+		return true
+	}
+	i := strings.LastIndex(saveName, autosaveSuffix+"_")
+	if i <= 0 {
+		return false
+	}
+	_, ok := autosaveIndex(saveName[:i], saveName)
+	return ok
+}
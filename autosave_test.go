@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAutosaveRetention(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 10, 0, 0, time.Local)
+	clock := func() time.Time { return now }
+	s, _ := newTestManager(t, WithClock(clock), WithAutosaveRetention(AutosaveRetention{Last: 3, Hourly: 2, Daily: 2}))
+	mustSave(t, s, "farm", `{}`)
+
+	// An autosave every 20 minutes for three days: 216 in all.
+	for i := 0; i < 3*24*3; i++ {
+		if err := s.writeAutosave("farm", `{}`); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(20 * time.Minute)
+	}
+	autosaves, err := s.GetAutosaves("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, info := range autosaves {
+		got = append(got, info.Name)
+	}
+	want := []string{
+		// The last three, the last two of them also the latest of the
+		// final hour and day.
+		"farm_autosave_216", "farm_autosave_215", "farm_autosave_214",
+		// The latest of the hour before.
+		"farm_autosave_213",
+		// The latest of the day before.
+		"farm_autosave_144",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetAutosaves = %v, want %v", got, want)
+	}
+
+	// A day later, the newest autosave is also the latest of its hour and
+	// day, and the one before it of the day before, so no others are kept.
+	now = now.Add(24 * time.Hour)
+	if err := s.writeAutosave("farm", `{}`); err != nil {
+		t.Fatal(err)
+	}
+	autosaves, err = s.GetAutosaves("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = got[:0]
+	for _, info := range autosaves {
+		got = append(got, info.Name)
+	}
+	want = []string{"farm_autosave_217", "farm_autosave_216", "farm_autosave_215"}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetAutosaves a day later = %v, want %v", got, want)
+	}
+}
+
+func TestIsAutosave(t *testing.T) {
+	for name, want := range map[string]bool{
+		"farm_autosave":    true,
+		"farm_autosave_3":  true,
+		"farm_autosave_03": false,
+		"farm_autosave_x":  false,
+		"farm":             false,
+	} {
+		if got := isAutosave(name); got != want {
+			t.Errorf("isAutosave(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// backupIDLayout is fixed-width so that backup IDs sort chronologically.
+const backupIDLayout = "20060102-150405.000000000"
+
+func (s *SaveManager) backupDir(saveName string) string {
+	return filepath.Join(s.dir(), "backups", saveName)
+}
+
+// BackupPolicy decides which overwrites of a save are backed up. The zero
+// value backs up every one.
+type BackupPolicy struct {
+	every       int
+	minInterval time.Duration
+}
+
+// BackupEvery backs up the first overwrite of each save after the manager
+// is created and then every nth one.
+func BackupEvery(n int) BackupPolicy {
+	return BackupPolicy{every: n}
+}
+
+// BackupMinInterval skips the backup of an overwrite when the save's newest
+// backup is less than d old.
+func BackupMinInterval(d time.Duration) BackupPolicy {
+	return BackupPolicy{minInterval: d}
+}
+
+// WithBackupPolicy sets which overwrites SaveGame backs up, so that frequent
+// autosaves do not fill the backups with near-identical copies. By default
+// every overwrite is backed up.
+func WithBackupPolicy(policy BackupPolicy) Option {
+	return func(s *SaveManager) {
+		s.backupPolicy = policy
+	}
+}
+
+// backupSaveIfDue is backupSave for an overwrite, skipped when the backup
+// policy says so.
+func (s *SaveManager) backupSaveIfDue(saveName string) error {
+	if s.maxBackups <= 0 {
+		return nil
+	}
+	if _, err := s.savePath(saveName); err != nil {
+		// Nothing to back up, which backupSave deals with.
+		return s.backupSave(saveName)
+	}
+	if every := s.backupPolicy.every; every > 1 {
+		s.backupCountsMu.Lock()
+		left := s.backupCounts[saveName]
+		if s.backupCounts == nil {
+			s.backupCounts = make(map[string]int)
+		}
+		if left > 0 {
+			s.backupCounts[saveName] = left - 1
+		} else {
+			s.backupCounts[saveName] = every - 1
+		}
+		s.backupCountsMu.Unlock()
+		if left > 0 {
+			return nil
+		}
+	}
+	if d := s.backupPolicy.minInterval; d > 0 {
+		ids, err := s.backupIDs(saveName)
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			last, err := time.Parse(backupIDLayout, ids[len(ids)-1])
+			if err == nil && s.now().Sub(last) < d {
+				return nil
+			}
+		}
+	}
+	return s.backupSave(saveName)
+}
+
+// backupSave copies the current contents of saveName, if any, into its backup
+// directory and prunes the oldest backups beyond the configured limit.
+func (s *SaveManager) backupSave(saveName string) error {
+	if s.maxBackups <= 0 {
+		return nil
+	}
+	filename, err := s.savePath(saveName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	dir := s.backupDir(saveName)
+	if err := s.fs.MkdirAll(dir, s.dirMode); err != nil {
+		return err
+	}
+	id := s.now().UTC().Format(backupIDLayout)
+	if err := s.copyFileAtomic(filename, filepath.Join(dir, id+s.fileSaveExt(filename)), s.fileMode); err != nil {
+		return fmt.Errorf("back up save %q: %w", saveName, err)
+	}
+	if err := s.copyFileAtomic(s.headerPath(saveName), filepath.Join(dir, id+".header"), s.fileMode); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("back up save %q: %w", saveName, err)
+	}
+	return s.pruneBackups(saveName)
+}
+
+func (s *SaveManager) pruneBackups(saveName string) error {
+	ids, err := s.backupIDs(saveName)
+	if err != nil {
+		return err
+	}
+	for len(ids) > s.maxBackups {
+		if err := s.removeBackup(saveName, ids[0]); err != nil {
+			return err
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// removeBackup removes the files of one backup of saveName.
+func (s *SaveManager) removeBackup(saveName, backupID string) error {
+	dir := s.backupDir(saveName)
+	for _, ext := range append(slices.Clone(s.fileExts), ".header") {
+		if err := s.fs.Remove(filepath.Join(dir, backupID+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupIDs returns the backup IDs of saveName, oldest first.
+func (s *SaveManager) backupIDs(saveName string) ([]string, error) {
+	files, err := s.fs.ReadDir(s.backupDir(saveName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if id, ok := s.trimSaveExt(file.Name()); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListBackups returns the backup IDs available for saveName, newest first.
+func (s *SaveManager) ListBackups(saveName string) ([]string, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return nil, err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	ids, err := s.backupIDs(saveName)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// RestoreBackup replaces saveName with the backup identified by backupID. The
+// current contents are backed up first, so a restore can itself be undone.
+func (s *SaveManager) RestoreBackup(saveName string, backupID string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	if _, err := time.Parse(backupIDLayout, backupID); err != nil {
+		return fmt.Errorf("invalid backup id %q", backupID)
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	data, ext, header, err := s.readBackup(saveName, backupID)
+	if err != nil {
+		return fmt.Errorf("restore backup %q of save %q: %w", backupID, saveName, err)
+	}
+	if err := s.checkNotReadOnly(saveName); err != nil {
+		return fmt.Errorf("restore backup %q of save %q: %w", backupID, saveName, err)
+	}
+	// Read the backup before taking a new one, since pruning may remove it.
+	if err := s.backupSave(saveName); err != nil {
+		return err
+	}
+	return s.installBackup(saveName, data, ext, header)
+}
+
+// ErrNothingToUndo is returned by UndoLastSave when a save has no backups
+// left to go back to.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// UndoLastSave puts saveName back as it was before it was last saved, from
+// the newest backup, and drops that backup, so that calling it again goes
+// back another save. Unlike RestoreBackup it does not back up the state it
+// replaces. Deltas saved since the last full save are discarded along with
+// it. It returns ErrNothingToUndo once the backups run out.
+func (s *SaveManager) UndoLastSave(saveName string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	ids, err := s.backupIDs(saveName)
+	if err != nil {
+		return fmt.Errorf("undo save %q: %w", saveName, err)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("undo save %q: %w", saveName, ErrNothingToUndo)
+	}
+	id := ids[len(ids)-1]
+	data, ext, header, err := s.readBackup(saveName, id)
+	if err != nil {
+		return fmt.Errorf("undo save %q: %w", saveName, err)
+	}
+	if err := s.installBackup(saveName, data, ext, header); err != nil {
+		return fmt.Errorf("undo save %q: %w", saveName, err)
+	}
+	if err := s.removeBackup(saveName, id); err != nil {
+		return fmt.Errorf("undo save %q: %w", saveName, err)
+	}
+	return nil
+}
+
+// readBackup returns the raw contents of a backup of saveName, the save
+// extension it was stored with and its header.
+func (s *SaveManager) readBackup(saveName, backupID string) ([]byte, string, saveHeader, error) {
+	var header saveHeader
+	dir := s.backupDir(saveName)
+	var ext string
+	var data []byte
+	var err error
+	for _, ext = range s.fileExts {
+		data, err = readFile(s.fs, filepath.Join(dir, backupID+ext))
+		if !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, "", header, err
+	}
+	if raw, err := readFile(s.fs, filepath.Join(dir, backupID+".header")); err == nil {
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return nil, "", header, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, "", header, err
+	}
+	return data, ext, header, nil
+}
+
+// installBackup replaces saveName with the raw backup contents data, stored
+// with the save extension ext. The caller must hold the save's lock.
+func (s *SaveManager) installBackup(saveName string, data []byte, ext string, header saveHeader) error {
+	if err := s.checkNotReadOnly(saveName); err != nil {
+		return err
+	}
+	s.uncacheSave(saveName)
+	err := s.writeFileAtomic(filepath.Join(s.dir(), saveName+ext), s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.removeOtherVariants(saveName, ext); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	if err := s.writeChecksum(saveName, sum[:]); err != nil {
+		return err
+	}
+	if err := s.writeHeader(saveName, header); err != nil {
+		return err
+	}
+	if err := s.fs.RemoveAll(s.deltaDir(saveName)); err != nil {
+		return err
+	}
+	return s.bumpGeneration(saveName)
+}
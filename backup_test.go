@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// tickingClock returns a clock that moves on by step every time it is read.
+func tickingClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestUndoLastSave(t *testing.T) {
+	s, _ := newTestManager(t, WithClock(tickingClock(time.Second)))
+	for day := 1; day <= 3; day++ {
+		mustSave(t, s, "farm", fmt.Sprintf(`{"day":%d}`, day))
+	}
+	for _, want := range []string{`{"day":2}`, `{"day":1}`} {
+		if err := s.UndoLastSave("farm"); err != nil {
+			t.Fatal(err)
+		}
+		if got := mustLoad(t, s, "farm"); got != want {
+			t.Errorf("LoadGame after undo = %s, want %s", got, want)
+		}
+	}
+	if err := s.UndoLastSave("farm"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("UndoLastSave with no backups = %v, want ErrNothingToUndo", err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("failed undo changed the save to %s", got)
+	}
+}
+
+func TestBackupEvery(t *testing.T) {
+	s, _ := newTestManager(t, WithClock(tickingClock(time.Second)), WithBackupPolicy(BackupEvery(3)))
+	for day := 0; day < 8; day++ {
+		mustSave(t, s, "farm", fmt.Sprintf(`{"day":%d}`, day))
+	}
+	// Seven overwrites, backed up at the first, fourth and seventh.
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 3 {
+		t.Errorf("ListBackups = %v, %v, want 3", ids, err)
+	}
+}
+
+func TestBackupMinInterval(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	s, _ := newTestManager(t, WithClock(clock), WithBackupPolicy(BackupMinInterval(time.Hour)))
+	for day := 0; day < 8; day++ {
+		mustSave(t, s, "farm", fmt.Sprintf(`{"day":%d}`, day))
+		now = now.Add(20 * time.Minute)
+	}
+	// Overwrites every 20 minutes, backed up at 20, 80 and 140 minutes.
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 3 {
+		t.Errorf("ListBackups = %v, %v, want 3", ids, err)
+	}
+}
+
+func TestBackupEverySaveByDefault(t *testing.T) {
+	s, _ := newTestManager(t, WithClock(tickingClock(time.Second)))
+	for day := 0; day < 4; day++ {
+		mustSave(t, s, "farm", fmt.Sprintf(`{"day":%d}`, day))
+	}
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 3 {
+		t.Errorf("ListBackups = %v, %v, want one per overwrite", ids, err)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// bundleNewExt marks a bundle part written by SaveBundle that has not been
+// moved into place yet.
+const bundleNewExt = ".new"
+
+// bundleCommitFile, in a bundle directory, lists the parts of a SaveBundle
+// that has been decided but may not be fully in place. While it exists the
+// bundle's parts are taken from its ".new" files where they have them.
+const bundleCommitFile = ".commit"
+
+func (s *SaveManager) bundleDir(saveName string) string {
+	return filepath.Join(s.dir(), "bundles", saveName)
+}
+
+// SaveBundle saves the named parts of a save, such as world, player and
+// inventory, all or nothing: if any part cannot be written, every part keeps
+// its previous contents, and a crash part way leaves either every previous
+// part or, once the next SaveBundle or LoadBundle finishes the job, every
+// new one. Parts missing from parts are removed from the bundle. Each part
+// must be JSON and is stored as plain JSON.
+func (s *SaveManager) SaveBundle(saveName string, parts map[string]string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(parts))
+	formatted := make(map[string]string, len(parts))
+	for part, data := range parts {
+		if err := validateSaveName(part); err != nil {
+			return fmt.Errorf("save bundle %q: part %q: %w", saveName, part, err)
+		}
+		data, err := s.formatSaveData(data)
+		if err != nil {
+			return fmt.Errorf("save bundle %q: part %q: %w", saveName, part, err)
+		}
+		names = append(names, part)
+		formatted[part] = data
+	}
+	sort.Strings(names)
+
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	dir := s.bundleDir(saveName)
+	if err := s.fs.MkdirAll(dir, s.dirMode); err != nil {
+		return fmt.Errorf("save bundle %q: %w", saveName, err)
+	}
+	if err := s.recoverBundle(dir); err != nil {
+		return fmt.Errorf("save bundle %q: %w", saveName, err)
+	}
+	if err := s.commitBundle(dir, names, formatted); err != nil {
+		return fmt.Errorf("save bundle %q: %w", saveName, err)
+	}
+	return nil
+}
+
+// commitBundle writes every part next to its current version and then lists
+// them in the commit file, which decides the bundle: a crash before that
+// leaves the previous parts, and one after it is rolled forward by
+// recoverBundle. Only then are the parts moved into place.
+func (s *SaveManager) commitBundle(dir string, names []string, parts map[string]string) error {
+	for _, part := range names {
+		data := parts[part]
+		err := s.writeFileAtomic(filepath.Join(dir, part+s.jsonExt+bundleNewExt), s.fileMode, func(w io.Writer) error {
+			_, err := io.WriteString(w, data)
+			return err
+		})
+		if err != nil {
+			s.discardBundle(dir)
+			return err
+		}
+	}
+	err := s.writeFileAtomic(filepath.Join(dir, bundleCommitFile), s.fileMode, func(w io.Writer) error {
+		for _, part := range names {
+			if _, err := io.WriteString(w, part+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.discardBundle(dir)
+		return err
+	}
+	return s.finishBundle(dir, names)
+}
+
+// recoverBundle completes or undoes a SaveBundle into dir that was
+// interrupted, e.g. by a crash: parts listed in the commit file are moved
+// into place, and parts written before the bundle was committed are thrown
+// away. The caller must hold the save's write lock.
+func (s *SaveManager) recoverBundle(dir string) error {
+	data, err := readFile(s.fs, filepath.Join(dir, bundleCommitFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return s.discardBundle(dir)
+	}
+	if err != nil {
+		return err
+	}
+	var names []string
+	if list := strings.TrimSuffix(string(data), "\n"); list != "" {
+		names = strings.Split(list, "\n")
+	}
+	for _, part := range names {
+		if err := validateSaveName(part); err != nil {
+			return fmt.Errorf("%w: commit file lists part %q", ErrInvalidSaveData, part)
+		}
+	}
+	return s.finishBundle(dir, names)
+}
+
+// finishBundle moves the committed parts names into place in dir, removes
+// the parts not among them, and then the commit file.
+func (s *SaveManager) finishBundle(dir string, names []string) error {
+	keep := make(map[string]bool, len(names))
+	for _, part := range names {
+		keep[part] = true
+		filename := filepath.Join(dir, part+s.jsonExt)
+		// A part already moved by an interrupted run has no new file left.
+		if err := s.fs.Rename(filename+bundleNewExt, filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	files, err := s.fs.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		part, ok := strings.CutSuffix(file.Name(), s.jsonExt)
+		if !ok || file.IsDir() || keep[part] {
+			continue
+		}
+		if err := s.fs.Remove(filepath.Join(dir, file.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if s.durable {
+		if err := s.syncDir(dir); err != nil {
+			return err
+		}
+	}
+	if err := s.fs.Remove(filepath.Join(dir, bundleCommitFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return s.discardBundle(dir)
+}
+
+// discardBundle removes the uncommitted new parts in dir.
+func (s *SaveManager) discardBundle(dir string) error {
+	files, err := s.fs.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), bundleNewExt) {
+			continue
+		}
+		err := s.fs.Remove(filepath.Join(dir, file.Name()))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverBundles runs recoverBundle on every bundle that needs it and returns
+// how many files and bytes that freed.
+func (s *SaveManager) recoverBundles() (int, int64, error) {
+	dirs, err := s.fs.ReadDir(filepath.Join(s.dir(), "bundles"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	removed := 0
+	var reclaimed int64
+	for _, dir := range dirs {
+		if !dir.IsDir() || validateSaveName(dir.Name()) != nil {
+			continue
+		}
+		files, size, err := s.recoverBundleOf(dir.Name())
+		removed += files
+		reclaimed += size
+		if err != nil {
+			return removed, reclaimed, err
+		}
+	}
+	return removed, reclaimed, nil
+}
+
+// recoverBundleOf runs recoverBundle on the bundle of saveName under the
+// save's lock, returning how many files and bytes that freed.
+func (s *SaveManager) recoverBundleOf(saveName string) (int, int64, error) {
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	dir := s.bundleDir(saveName)
+	files, err := s.fs.ReadDir(dir)
+	if err != nil || !needsRecovery(files) {
+		return 0, 0, err
+	}
+	count, size := dirSize(files)
+	if err := s.recoverBundle(dir); err != nil {
+		return 0, 0, err
+	}
+	files, err = s.fs.ReadDir(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	countAfter, sizeAfter := dirSize(files)
+	return count - countAfter, size - sizeAfter, nil
+}
+
+// dirSize returns how many files there are in a directory listing and their
+// total size.
+func dirSize(files []os.DirEntry) (int, int64) {
+	count := 0
+	var size int64
+	for _, file := range files {
+		if fi, err := file.Info(); err == nil && !file.IsDir() {
+			count++
+			size += fi.Size()
+		}
+	}
+	return count, size
+}
+
+// needsRecovery reports whether the bundle directory listing files shows an
+// interrupted SaveBundle.
+func needsRecovery(files []os.DirEntry) bool {
+	for _, file := range files {
+		name := file.Name()
+		if name == bundleCommitFile || strings.HasSuffix(name, bundleNewExt) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadBundle returns the parts saved with SaveBundle, keyed by part name.
+func (s *SaveManager) LoadBundle(saveName string) (map[string]string, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return nil, err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	dir := s.bundleDir(saveName)
+	files, err := s.fs.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load bundle %q: %w", saveName, ErrSaveNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load bundle %q: %w", saveName, err)
+	}
+	if needsRecovery(files) {
+		mu.RUnlock()
+		mu.Lock()
+		err := s.recoverBundle(dir)
+		mu.Unlock()
+		mu.RLock()
+		if err != nil {
+			return nil, fmt.Errorf("load bundle %q: %w", saveName, err)
+		}
+		if files, err = s.fs.ReadDir(dir); err != nil {
+			return nil, fmt.Errorf("load bundle %q: %w", saveName, err)
+		}
+	}
+	parts := make(map[string]string)
+	for _, file := range files {
+		part, ok := strings.CutSuffix(file.Name(), s.jsonExt)
+		if !ok || file.IsDir() {
+			continue
+		}
+		data, err := readFile(s.fs, filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("load bundle %q: %w", saveName, err)
+		}
+		parts[part] = string(data)
+	}
+	return parts, nil
+}
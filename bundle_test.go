@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bundleFiles returns the files of the bundle saveName on mem by path.
+func bundleFiles(mem *memFS, saveName string) map[string]string {
+	files := make(map[string]string)
+	for name, data := range mem.files {
+		if strings.HasPrefix(name, "/data/bundles/"+saveName+"/") {
+			files[name] = string(data)
+		}
+	}
+	return files
+}
+
+func TestSaveBundleFailedPartChangesNothing(t *testing.T) {
+	mem := newMemFS()
+	s, err := NewSaveManagerWithDir("/data", WithFileSystem(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{"world": `{"day":1}`, "player": `{"hp":10}`, "inventory": `{"seeds":3}`}
+	if err := s.SaveBundle("farm", parts); err != nil {
+		t.Fatal(err)
+	}
+	before := bundleFiles(mem, "farm")
+
+	// Fail writing the third part.
+	written := 0
+	mem.renameHook = func(oldpath, newpath string) error {
+		if strings.HasSuffix(newpath, ".json.new") {
+			if written++; written == 3 {
+				return errors.New("disk failed")
+			}
+		}
+		return nil
+	}
+	err = s.SaveBundle("farm", map[string]string{"world": `{"day":2}`, "player": `{"hp":9}`, "inventory": `{"seeds":2}`})
+	if err == nil {
+		t.Fatal("SaveBundle succeeded with a failing third part")
+	}
+	mem.renameHook = nil
+
+	if after := bundleFiles(mem, "farm"); !maps.Equal(after, before) {
+		t.Errorf("bundle files changed on disk:\n got %v\nwant %v", after, before)
+	}
+	got, err := s.LoadBundle("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, parts) {
+		t.Errorf("LoadBundle = %v, want %v", got, parts)
+	}
+}
+
+func TestSaveBundleRecoversInterruptedCommit(t *testing.T) {
+	mem := newMemFS()
+	s, err := NewSaveManagerWithDir("/data", WithFileSystem(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveBundle("farm", map[string]string{"world": `1`, "player": `1`, "inventory": `1`}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Crash after the commit file is written, partway through moving the
+	// parts into place.
+	moved := 0
+	mem.renameHook = func(oldpath, newpath string) error {
+		if strings.HasSuffix(oldpath, ".json.new") {
+			if moved++; moved == 2 {
+				return errors.New("crashed")
+			}
+		}
+		return nil
+	}
+	next := map[string]string{"world": `2`, "player": `2`}
+	if err := s.SaveBundle("farm", next); err == nil {
+		t.Fatal("SaveBundle succeeded through a crash")
+	}
+	mem.renameHook = nil
+
+	got, err := s.LoadBundle("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, next) {
+		t.Errorf("LoadBundle = %v, want the committed bundle %v", got, next)
+	}
+	for name := range bundleFiles(mem, "farm") {
+		if !strings.HasSuffix(name, defaultSaveExt) {
+			t.Errorf("%s left behind", name)
+		}
+	}
+}
+
+func TestLoadBundleNotFound(t *testing.T) {
+	s, _ := newTestManager(t)
+	if _, err := s.LoadBundle("missing"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadBundle = %v, want ErrSaveNotFound", err)
+	}
+}
+
+func TestBundleFollowsItsSave(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.SaveBundle("farm", map[string]string{"world": `{"day":1}`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RenameSave("farm", "valley"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DuplicateSave("valley", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	for _, saveName := range []string{"valley", "copy"} {
+		if parts, err := s.LoadBundle(saveName); err != nil || parts["world"] != `{"day":1}` {
+			t.Errorf("LoadBundle(%q) = %v, %v", saveName, parts, err)
+		}
+	}
+	if err := s.DeleteSave("valley"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bundles", "valley")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("bundle not deleted with its save: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// saveCache keeps the most recently loaded saves in memory, so that menus
+// refreshing their previews do not read the same file again and again.
+type saveCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+// cacheEntry is a loaded save along with the file it came from, which must
+// be unchanged for the entry to be used.
+type cacheEntry struct {
+	name     string
+	filename string
+	modTime  time.Time
+	size     int64
+	data     string
+}
+
+// WithCache makes LoadGame keep up to maxEntries saves in memory, dropping
+// the least recently loaded first. An entry is used only while its file is
+// unchanged on disk, and is dropped whenever the save is written, deleted or
+// renamed.
+func WithCache(maxEntries int) Option {
+	return func(s *SaveManager) {
+		s.cache.max = maxEntries
+	}
+}
+
+// ClearCache empties the cache set up with WithCache.
+func (s *SaveManager) ClearCache() {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	s.cache.order = nil
+	s.cache.entries = nil
+}
+
+// cachedSave returns the cached contents of saveName if its file has not
+// changed since they were cached. The caller must hold the save's lock.
+func (s *SaveManager) cachedSave(saveName string) (string, bool) {
+	if s.cache.max <= 0 {
+		return "", false
+	}
+	entry, ok := s.cacheStamp(saveName)
+	if !ok {
+		return "", false
+	}
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	elem, ok := s.cache.entries[saveName]
+	if !ok {
+		return "", false
+	}
+	cached := elem.Value.(*cacheEntry)
+	if cached.filename != entry.filename || !cached.modTime.Equal(entry.modTime) || cached.size != entry.size {
+		s.cache.order.Remove(elem)
+		delete(s.cache.entries, saveName)
+		return "", false
+	}
+	s.cache.order.MoveToFront(elem)
+	return cached.data, true
+}
+
+// cacheSave stores saveData as the contents of saveName. The caller must
+// hold the save's lock, so that no write can come between reading the save
+// and caching it.
+func (s *SaveManager) cacheSave(saveName, saveData string) {
+	if s.cache.max <= 0 {
+		return
+	}
+	entry, ok := s.cacheStamp(saveName)
+	if !ok {
+		return
+	}
+	entry.data = saveData
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	if s.cache.entries == nil {
+		s.cache.order = list.New()
+		s.cache.entries = make(map[string]*list.Element)
+	}
+	if elem, ok := s.cache.entries[saveName]; ok {
+		s.cache.order.Remove(elem)
+	}
+	s.cache.entries[saveName] = s.cache.order.PushFront(entry)
+	for s.cache.order.Len() > s.cache.max {
+		oldest := s.cache.order.Back()
+		s.cache.order.Remove(oldest)
+		delete(s.cache.entries, oldest.Value.(*cacheEntry).name)
+	}
+}
+
+// cacheStamp returns a cache entry, without data, describing the file
+// currently holding saveName.
+func (s *SaveManager) cacheStamp(saveName string) (*cacheEntry, bool) {
+	filename, err := s.savePath(saveName)
+	if err != nil {
+		return nil, false
+	}
+	fi, err := s.fs.Stat(filename)
+	if err != nil {
+		return nil, false
+	}
+	return &cacheEntry{name: saveName, filename: filename, modTime: fi.ModTime(), size: fi.Size()}, true
+}
+
+// uncacheSave drops saveName from the cache. Anything that changes a save
+// on disk calls it, since the file's modification time may not.
+func (s *SaveManager) uncacheSave(saveName string) {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	if elem, ok := s.cache.entries[saveName]; ok {
+		s.cache.order.Remove(elem)
+		delete(s.cache.entries, saveName)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tamperSave rewrites the save file of saveName behind the manager's back,
+// keeping its size and modification time so only a fresh read can tell.
+func tamperSave(t *testing.T, dir, saveName, saveData string) {
+	t.Helper()
+	filename := filepath.Join(dir, saveName+defaultSaveExt)
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(saveData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filename, fi.ModTime(), fi.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	// The checksum would otherwise catch the change.
+	os.Remove(filepath.Join(dir, saveName+".sha256"))
+}
+
+func TestCacheHit(t *testing.T) {
+	s, dir := newTestManager(t, WithCache(2))
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustLoad(t, s, "farm")
+	tamperSave(t, dir, "farm", `{"day":9}`)
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("second LoadGame = %s, want it served from the cache", got)
+	}
+	s.ClearCache()
+	if got := mustLoad(t, s, "farm"); got != `{"day":9}` {
+		t.Errorf("LoadGame after ClearCache = %s, want it read from disk", got)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	s, dir := newTestManager(t, WithCache(2))
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustLoad(t, s, "farm")
+	mustSave(t, s, "farm", `{"day":2}`)
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame after SaveGame = %s", got)
+	}
+
+	if err := s.SaveDelta("farm", `{"day":2}`, `{"day":3}`); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":3}` {
+		t.Errorf("LoadGame after SaveDelta = %s", got)
+	}
+
+	mustSave(t, s, "mine", `{"depth":1}`)
+	mustLoad(t, s, "mine")
+	tamperSave(t, dir, "mine", `{"depth":9}`)
+	later := time.Now().Add(time.Hour)
+	setModTime(t, dir, "mine", later)
+	if got := mustLoad(t, s, "mine"); got != `{"depth":9}` {
+		t.Errorf("LoadGame after the file changed = %s", got)
+	}
+
+	if err := s.RenameSave("farm", "valley"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadGame("farm"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadGame of a renamed save = %v, want ErrSaveNotFound", err)
+	}
+	if err := s.DeleteSave("valley"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadGame("valley"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadGame of a deleted save = %v, want ErrSaveNotFound", err)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	s, _ := newTestManager(t, WithCache(2))
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			saveName := fmt.Sprint("farm", w%3)
+			for i := 0; i < 50; i++ {
+				if err := s.SaveGame(saveName, fmt.Sprintf(`{"day":%d}`, i)); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := s.LoadGame(saveName); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadGameUnverified(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		s, _ := newTestManager(t, WithCompression(compress))
+		mustSave(t, s, "farm", `{"day":1}`)
+		if got, err := s.LoadGameUnverified("farm"); err != nil || got != `{"day":1}` {
+			t.Errorf("compress=%v: LoadGameUnverified = %s, %v", compress, got, err)
+		}
+
+		// A wrong checksum fails LoadGame but not the unverified path.
+		if err := os.WriteFile(s.checksumPath("farm"), []byte(strings.Repeat("0", 64)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.LoadGame("farm"); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("compress=%v: LoadGame with bad checksum = %v, want ErrChecksumMismatch", compress, err)
+		}
+		if got, err := s.LoadGameUnverified("farm"); err != nil || got != `{"day":1}` {
+			t.Errorf("compress=%v: LoadGameUnverified with bad checksum = %s, %v", compress, got, err)
+		}
+	}
+}
+
+func TestLoadGameUnverifiedMissing(t *testing.T) {
+	s, _ := newTestManager(t)
+	if _, err := s.LoadGameUnverified("nobody"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadGameUnverified of a missing save = %v, want ErrNotExist", err)
+	}
+}
+
+func BenchmarkLoadGame(b *testing.B) {
+	s, err := NewSaveManagerWithDir(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := s.SaveGame("farm", `{"map":"`+strings.Repeat("x", 20<<20)+`"}`); err != nil {
+		b.Fatal(err)
+	}
+	for _, bc := range []struct {
+		name string
+		load func(string) (string, error)
+	}{
+		{"verified", s.LoadGame},
+		{"unverified", s.LoadGameUnverified},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := bc.load("farm"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CleanupTempFiles removes the temporary files left in the data directory
+// and the backup, delta and bundle directories by writes that were
+// interrupted, e.g. by a crash, finishes or undoes interrupted SaveBundle
+// calls, and returns how many files it removed. Call it at startup, before
+// anything is saved, so that no write in progress loses its file.
+func (s *SaveManager) CleanupTempFiles() (int, error) {
+	removed, _, err := s.removeTempFiles(time.Time{})
+	if err != nil {
+		return removed, err
+	}
+	recovered, _, err := s.recoverBundles()
+	return removed + recovered, err
+}
+
+// removeTempFiles removes the temporary files last modified before cutoff, or
+// all of them if cutoff is zero, and returns how many files and bytes it
+// removed.
+func (s *SaveManager) removeTempFiles(cutoff time.Time) (int, int64, error) {
+	dirs := []string{s.dir()}
+	for _, root := range []string{"backups", "bundles", "deltas"} {
+		subdirs, err := s.fs.ReadDir(filepath.Join(s.dir(), root))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, 0, err
+		}
+		for _, dir := range subdirs {
+			if dir.IsDir() {
+				dirs = append(dirs, filepath.Join(s.dir(), root, dir.Name()))
+			}
+		}
+	}
+	removed := 0
+	var reclaimed int64
+	for _, dir := range dirs {
+		files, err := s.fs.ReadDir(dir)
+		if err != nil {
+			return removed, reclaimed, err
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), tmpExt) {
+				continue
+			}
+			fi, err := file.Info()
+			if err != nil {
+				continue
+			}
+			if !cutoff.IsZero() && !fi.ModTime().Before(cutoff) {
+				continue
+			}
+			err = s.fs.Remove(filepath.Join(dir, file.Name()))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return removed, reclaimed, err
+			}
+			removed++
+			reclaimed += fi.Size()
+		}
+	}
+	return removed, reclaimed, nil
+}
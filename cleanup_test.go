@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCleanupTempFiles(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2}`)
+	// Left by a save killed between writing and renaming.
+	stale := []string{
+		filepath.Join(dir, "mine"+defaultSaveExt+tmpExt),
+		filepath.Join(dir, "backups", "farm", "20200101-000000.000000000"+defaultSaveExt+tmpExt),
+	}
+	for _, name := range stale {
+		if err := os.WriteFile(name, []byte(`{"da`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := s.GetAllSaves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"farm"}) {
+		t.Errorf("GetAllSaves = %v, want temp files left out", names)
+	}
+
+	n, err := s.CleanupTempFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(stale) {
+		t.Errorf("CleanupTempFiles removed %d files, want %d", n, len(stale))
+	}
+	for _, name := range stale {
+		if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s not removed: %v", name, err)
+		}
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 1 {
+		t.Errorf("ListBackups = %v, %v, want the backup kept", ids, err)
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import "time"
+
+// WithClock makes the manager take the current time from now instead of
+// time.Now, for backup IDs, history entries, autosave retention and the
+// other features that go by the time, so that tests can drive them with a
+// fake clock.
+func WithClock(now func() time.Time) Option {
+	return func(s *SaveManager) {
+		s.nowFunc = now
+	}
+}
+
+func (s *SaveManager) now() time.Time {
+	return s.nowFunc()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFakeClockDrivesBackupPruning(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	s, _ := newTestManager(t, WithClock(clock), WithMaxBackups(2), WithBackupPolicy(BackupMinInterval(time.Hour)))
+	for day := 0; day < 6; day++ {
+		mustSave(t, s, "farm", fmt.Sprintf(`{"day":%d}`, day))
+		now = now.Add(40 * time.Minute)
+	}
+	// Overwrites every 40 minutes, backed up at 40, 120 and 200 minutes and
+	// pruned to the newest two.
+	want := []string{
+		time.Date(2026, 1, 1, 15, 20, 0, 0, time.UTC).Format(backupIDLayout),
+		time.Date(2026, 1, 1, 14, 0, 0, 0, time.UTC).Format(backupIDLayout),
+	}
+	if ids, err := s.ListBackups("farm"); err != nil || !slices.Equal(ids, want) {
+		t.Errorf("ListBackups = %v, %v, want %v", ids, err, want)
+	}
+}
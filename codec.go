@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Codec is the format saves are stored in on disk. SaveGame and LoadGame
+// still deal in JSON; the codec converts it on the way in and out.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	// Extension is the file extension of saves in this format, such as
+	// ".json".
+	Extension() string
+}
+
+// JSONCodec stores saves as JSON text. It is the default.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func (JSONCodec) Extension() string { return defaultSaveExt }
+
+// builtinCodecs are the codecs whose saves are always read, whichever one
+// writes new saves, so that a data directory can hold both while players
+// move over.
+var builtinCodecs = []Codec{JSONCodec{}, MessagePackCodec{}}
+
+// WithCodec sets the format new saves are written in, along with their file
+// extension, which replaces any set with WithSaveExt. Saves written in the
+// other built-in format are still listed and loaded.
+func WithCodec(codec Codec) Option {
+	return func(s *SaveManager) {
+		s.codec = codec
+		s.saveExt = codec.Extension()
+	}
+}
+
+// saveFormat pairs a save file extension with the codec of its files.
+type saveFormat struct {
+	ext   string
+	codec Codec
+}
+
+// initFormats works out the formats saves are read in: the configured one
+// first, then the other built-in ones.
+func (s *SaveManager) initFormats() {
+	s.compressedExt = s.saveExt + gzExt
+	s.formats = []saveFormat{{s.saveExt, s.codec}}
+	s.jsonExt = s.saveExt
+	if !isJSONCodec(s.codec) {
+		s.jsonExt = defaultSaveExt
+	}
+	for _, codec := range builtinCodecs {
+		if reflect.TypeOf(codec) == reflect.TypeOf(s.codec) || codec.Extension() == s.saveExt {
+			continue
+		}
+		s.formats = append(s.formats, saveFormat{codec.Extension(), codec})
+	}
+	s.fileExts = nil
+	for _, f := range s.formats {
+		s.fileExts = append(s.fileExts, f.ext+gzExt, f.ext)
+	}
+}
+
+// codecFor returns the codec of the save file filename.
+func (s *SaveManager) codecFor(filename string) Codec {
+	ext := strings.TrimSuffix(s.fileSaveExt(filename), gzExt)
+	for _, f := range s.formats {
+		if f.ext == ext {
+			return f.codec
+		}
+	}
+	return s.codec
+}
+
+func isJSONCodec(codec Codec) bool {
+	_, ok := codec.(JSONCodec)
+	return ok
+}
+
+// encodeSave converts the JSON saveData to codec's format.
+func encodeSave(codec Codec, saveData []byte) ([]byte, error) {
+	if isJSONCodec(codec) {
+		return saveData, nil
+	}
+	state, err := decodeState(string(saveData))
+	if err != nil {
+		return nil, err
+	}
+	return codec.Marshal(state)
+}
+
+// decodeSave converts data from codec's format back to JSON.
+func decodeSave(codec Codec, data []byte) ([]byte, error) {
+	if isJSONCodec(codec) {
+		return data, nil
+	}
+	var state any
+	if err := codec.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return encodeState(state)
+}
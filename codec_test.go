@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	saveData := `{"big":18446744073709551615,"crops":[1.5,true,null,"kale"],"f":2.0,"player":{"name":"Ann"},"s":"` + strings.Repeat("z", 300) + `"}`
+	for _, codec := range []Codec{JSONCodec{}, MessagePackCodec{}} {
+		s, dir := newTestManager(t, WithCodec(codec))
+		mustSave(t, s, "farm", saveData)
+		if _, err := os.Stat(filepath.Join(dir, "farm"+codec.Extension())); err != nil {
+			t.Errorf("%T: %v", codec, err)
+		}
+		if got := mustLoad(t, s, "farm"); got != saveData {
+			t.Errorf("%T: LoadGame = %s, want %s", codec, got, saveData)
+		}
+		if got, err := s.LoadField("farm", "player.name"); err != nil || got != "Ann" {
+			t.Errorf("%T: LoadField = %q, %v", codec, got, err)
+		}
+	}
+}
+
+func TestCodecMixedDataDir(t *testing.T) {
+	dir := t.TempDir()
+	js, err := NewSaveManagerWithDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp, err := NewSaveManagerWithDir(dir, WithCodec(MessagePackCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, js, "old", `{"day":1}`)
+	mustSave(t, mp, "new", `{"day":2}`)
+
+	for _, s := range []*SaveManager{js, mp} {
+		names, err := s.GetAllSaves()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(names, []string{"new", "old"}) {
+			t.Errorf("GetAllSaves = %v, want [new old]", names)
+		}
+		if got := mustLoad(t, s, "old"); got != `{"day":1}` {
+			t.Errorf("LoadGame(old) = %s", got)
+		}
+		if got := mustLoad(t, s, "new"); got != `{"day":2}` {
+			t.Errorf("LoadGame(new) = %s", got)
+		}
+	}
+
+	// Saving in the configured format replaces the other one.
+	mustSave(t, mp, "old", `{"day":3}`)
+	if _, err := os.Stat(filepath.Join(dir, "old.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("JSON variant left behind: %v", err)
+	}
+	if got := mustLoad(t, js, "old"); got != `{"day":3}` {
+		t.Errorf("LoadGame(old) = %s", got)
+	}
+}
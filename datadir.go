@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// NewSaveManager returns a SaveManager that keeps its saves in the
+// platform's data directory: $XDG_DATA_HOME/valley-legend, or
+// ~/.local/share/valley-legend, on Linux and ~/.valley-legend/data elsewhere.
+// On Linux, saves left in ~/.valley-legend/data by older versions are moved
+// over, except those the data directory already has. If the data directory
+// can't be written, e.g. on a machine with a read-only home directory, it
+// falls back to a directory under the system temporary directory. DataDir
+// reports the choice and DataDirFallback why it was made.
+func NewSaveManager(opts ...Option) (*SaveManager, error) {
+	dirs, locateErr := defaultDataDirs()
+	s, err := newSaveManagerIn(dirs, opts...)
+	if err != nil {
+		return nil, errors.Join(locateErr, err)
+	}
+	if locateErr != nil {
+		s.dataDirFallback = errors.Join(locateErr, s.dataDirFallback)
+		s.reportError(locateErr)
+	}
+	// Profiles postdate the move, so a directory that has them needs none.
+	if legacy := legacyDataDir(); legacy != "" && s.dir() == dirs[0] && s.CurrentProfile() == "" {
+		s.adoptLegacyDir(legacy)
+	}
+	return s, nil
+}
+
+// defaultDataDirs lists the directories NewSaveManager tries, in order, and
+// why the platform's data directory is missing from them, if it is.
+func defaultDataDirs() ([]string, error) {
+	var dirs []string
+	dir, err := platformDataDir()
+	if err == nil {
+		dirs = append(dirs, dir)
+	} else {
+		err = fmt.Errorf("locate data directory: %w", err)
+	}
+	return append(dirs, filepath.Join(os.TempDir(), "valley-legend", "data")), err
+}
+
+// platformDataDir returns where saves belong on this platform.
+func platformDataDir() (string, error) {
+	if runtime.GOOS == "linux" {
+		// The XDG spec says relative paths are invalid and must be ignored.
+		if xdg := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(xdg) {
+			return filepath.Join(xdg, "valley-legend"), nil
+		}
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "linux" {
+		return filepath.Join(homeDir, ".local", "share", "valley-legend"), nil
+	}
+	return filepath.Join(homeDir, ".valley-legend", "data"), nil
+}
+
+// legacyDataDir returns where older versions kept saves if that differs from
+// platformDataDir, or "".
+func legacyDataDir() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".valley-legend", "data")
+}
+
+// adoptLegacyDir moves the saves in legacy, if any, into the data directory.
+// Saves the data directory already has are left in legacy, as
+// moveDataFiles describes, so that a stale copy never replaces a newer one.
+// An interrupted move is completed the next time.
+func (s *SaveManager) adoptLegacyDir(legacy string) {
+	files, err := s.dataFiles(legacy, "")
+	if err != nil || len(files) == 0 {
+		return
+	}
+	if err := s.moveDataFiles(legacy, s.dir(), files); err != nil {
+		s.reportError(fmt.Errorf("move saves from %s: %w", legacy, err))
+	}
+}
+
+// newSaveManagerIn returns a SaveManager for the first of dirs that can be
+// written, recording why it passed over the others.
+func newSaveManagerIn(dirs []string, opts ...Option) (*SaveManager, error) {
+	var errs []error
+	for _, dir := range dirs {
+		s, err := NewSaveManagerWithDir(dir, opts...)
+		if err == nil {
+			err = s.checkWritable()
+		}
+		if err == nil {
+			s.dataDirFallback = errors.Join(errs...)
+			for _, err := range errs {
+				s.reportError(err)
+			}
+			return s, nil
+		}
+		errs = append(errs, fmt.Errorf("data directory %s is not usable: %w", dir, err))
+	}
+	return nil, fmt.Errorf("no usable data directory: %w", errors.Join(errs...))
+}
+
+// checkWritable fails if files cannot be created in the data directory.
+func (s *SaveManager) checkWritable() error {
+	probe := filepath.Join(s.dir(), ".write_test")
+	err := s.writeFileAtomic(probe, s.fileMode, func(w io.Writer) error {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.fs.Remove(probe)
+}
+
+// DataDir returns the absolute path of the directory the saves are kept in,
+// for showing to the player or opening in a file manager.
+func (s *SaveManager) DataDir() string {
+	if dir, err := filepath.Abs(s.dir()); err == nil {
+		return dir
+	}
+	return s.dir()
+}
+
+// DataDirFallback returns why NewSaveManager did not use the platform's data
+// directory, for support to diagnose, or "" if it did.
+func (s *SaveManager) DataDirFallback() string {
+	if s.dataDirFallback == nil {
+		return ""
+	}
+	return s.dataDirFallback.Error()
+}
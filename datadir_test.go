@@ -0,0 +1,233 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewSaveManagerWithDirUnwritable(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSaveManagerWithDir(filepath.Join(file, "saves"))
+	if err == nil {
+		t.Fatal("NewSaveManagerWithDir succeeded under a regular file")
+	}
+	if s != nil {
+		t.Errorf("NewSaveManagerWithDir returned a manager along with %v", err)
+	}
+}
+
+func TestNewSaveManagerUnwritableHome(t *testing.T) {
+	// Directories can't be created under a regular file, even as root.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", filepath.Join(file, "home"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(file, "xdg"))
+	t.Setenv("TMPDIR", filepath.Join(file, "tmp"))
+
+	s, err := NewSaveManager()
+	if err == nil {
+		t.Fatal("NewSaveManager succeeded with an unwritable home directory")
+	}
+	if s != nil {
+		t.Errorf("NewSaveManager returned a manager along with %v", err)
+	}
+}
+
+func TestNewSaveManagerFallsBack(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fallback := filepath.Join(t.TempDir(), "fallback")
+	var reported []error
+	s, err := newSaveManagerIn([]string{filepath.Join(file, "data"), file, fallback},
+		WithErrorHandler(func(err error) { reported = append(reported, err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.DataDir() != fallback {
+		t.Fatalf("DataDir = %s, want %s", s.DataDir(), fallback)
+	}
+	mustSave(t, s, "farm", `{}`)
+	if reason := s.DataDirFallback(); !strings.Contains(reason, filepath.Join(file, "data")) || !strings.Contains(reason, file+" is not usable") {
+		t.Errorf("DataDirFallback = %q, want both skipped directories", reason)
+	}
+	if len(reported) != 2 {
+		t.Errorf("error handler got %v, want both skipped directories", reported)
+	}
+	s, err = newSaveManagerIn([]string{fallback})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason := s.DataDirFallback(); reason != "" {
+		t.Errorf("DataDirFallback without a fallback = %q", reason)
+	}
+
+	if _, err := newSaveManagerIn([]string{file}); err == nil {
+		t.Error("newSaveManagerIn succeeded with no usable directory")
+	}
+}
+
+func TestNewSaveManagerXDGDataHome(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_DATA_HOME is only consulted on Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	xdg := filepath.Join(t.TempDir(), "xdg")
+	t.Setenv("XDG_DATA_HOME", xdg)
+
+	s, err := NewSaveManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(xdg, "valley-legend"); s.DataDir() != want {
+		t.Errorf("DataDir = %s, want %s", s.DataDir(), want)
+	}
+
+	// Relative paths are invalid under the spec and ignored.
+	t.Setenv("XDG_DATA_HOME", "relative")
+	s, err = NewSaveManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".local", "share", "valley-legend"); s.DataDir() != want {
+		t.Errorf("DataDir with a relative XDG_DATA_HOME = %s, want %s", s.DataDir(), want)
+	}
+}
+
+func TestNewSaveManagerMovesLegacySaves(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only Linux moved its data directory")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(t.TempDir(), "xdg"))
+	legacyDir := filepath.Join(home, ".valley-legend", "data")
+	legacy, err := NewSaveManagerWithDir(legacyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, legacy, "farm", `{"day":1}`)
+	mustSave(t, legacy, "farm", `{"day":2}`)
+	if err := legacy.SetLastSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSaveManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "farm" {
+		t.Errorf("GetLastSave = %q, %v", last, err)
+	}
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 1 {
+		t.Errorf("ListBackups = %v, %v", ids, err)
+	}
+	if left, err := os.ReadDir(legacyDir); err != nil || len(left) != 0 {
+		t.Errorf("legacy directory holds %v, %v after the move", left, err)
+	}
+}
+
+func TestNewSaveManagerKeepsNewerSaves(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only Linux moved its data directory")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	xdg := filepath.Join(t.TempDir(), "xdg")
+	t.Setenv("XDG_DATA_HOME", xdg)
+	s, err := NewSaveManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":9}`)
+	legacyDir := filepath.Join(home, ".valley-legend", "data")
+	legacy, err := NewSaveManagerWithDir(legacyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, legacy, "farm", `{"day":1}`)
+	mustSave(t, legacy, "ranch", `{"day":3}`)
+
+	// Every launch looks for legacy saves again.
+	for i := 0; i < 2; i++ {
+		if s, err = NewSaveManager(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":9}` {
+		t.Errorf("legacy save replaced the newer one: %s", got)
+	}
+	if got := mustLoad(t, s, "ranch"); got != `{"day":3}` {
+		t.Errorf("LoadGame(ranch) = %s", got)
+	}
+	if got := mustLoad(t, legacy, "farm"); got != `{"day":1}` {
+		t.Errorf("clashing legacy save = %s, want it left in place", got)
+	}
+}
+
+func TestNewSaveManagerIgnoresLegacyLeftovers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only Linux moved its data directory")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	xdg := filepath.Join(t.TempDir(), "xdg")
+	t.Setenv("XDG_DATA_HOME", xdg)
+	legacyDir := filepath.Join(home, ".valley-legend", "data")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"farm.lock", "farm.json" + tmpExt} {
+		if err := os.WriteFile(filepath.Join(legacyDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := NewSaveManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := dirFiles(t, s.DataDir()); len(files) != 0 {
+		t.Errorf("data directory holds %v, want nothing moved", files)
+	}
+	if files := dirFiles(t, legacyDir); len(files) != 2 {
+		t.Errorf("legacy directory holds %v, want the leftovers untouched", files)
+	}
+}
+
+func TestDataDirIsAbsolute(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	s, err := NewSaveManagerWithDir("saves")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := filepath.Abs("saves")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.DataDir() != want {
+		t.Errorf("DataDir = %s, want %s", s.DataDir(), want)
+	}
+}
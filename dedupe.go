@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// ErrNotDuplicate is returned by DeduplicateSaves when a save to remove does
+// not hold the same data as the one kept.
+var ErrNotDuplicate = errors.New("saves are not identical")
+
+// FindDuplicates groups the saves holding identical data, keyed by the
+// SHA-256 of that data, so the game can offer to delete redundant copies.
+// Only groups of two or more are returned, each sorted by name. Autosaves
+// are left out, since they normally match the save they belong to.
+func (s *SaveManager) FindDuplicates() (map[string][]string, error) {
+	names, err := s.saveNames()
+	if err != nil {
+		return nil, fmt.Errorf("find duplicate saves: %w", err)
+	}
+	groups := make(map[string][]string)
+	for _, name := range names {
+		if isAutosave(name) {
+			continue
+		}
+		sum, err := s.contentHash(name)
+		if errors.Is(err, os.ErrNotExist) {
+			// Deleted since it was listed.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("find duplicate saves: save %q: %w", name, err)
+		}
+		groups[sum] = append(groups[sum], name)
+	}
+	for sum, group := range groups {
+		if len(group) < 2 {
+			delete(groups, sum)
+			continue
+		}
+		slices.Sort(group)
+	}
+	return groups, nil
+}
+
+// DeduplicateSaves deletes the saves in remove after checking that each
+// holds the same data as keep, failing with ErrNotDuplicate before deleting
+// anything if one does not. The last-save marker moves to keep if it named
+// one of the deleted saves.
+func (s *SaveManager) DeduplicateSaves(keep string, remove []string) error {
+	if err := validateSaveName(keep); err != nil {
+		return err
+	}
+	want, err := s.contentHash(keep)
+	if err != nil {
+		return fmt.Errorf("deduplicate save %q: %w", keep, err)
+	}
+	for _, name := range remove {
+		if err := validateSaveName(name); err != nil {
+			return err
+		}
+		if name == keep {
+			return fmt.Errorf("deduplicate save %q: cannot remove the save kept", keep)
+		}
+		sum, err := s.contentHash(name)
+		if err != nil {
+			return fmt.Errorf("deduplicate save %q: %w", name, err)
+		}
+		if sum != want {
+			return fmt.Errorf("deduplicate save %q: %q: %w", keep, name, ErrNotDuplicate)
+		}
+	}
+	for _, name := range remove {
+		if err := s.replaceLastSave(name, keep); err != nil {
+			return err
+		}
+		if err := s.DeleteSave(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contentHash returns the hex SHA-256 of the data saveName holds, decrypted
+// and decompressed so that saves written with different settings still
+// match, and with any deltas applied.
+func (s *SaveManager) contentHash(saveName string) (string, error) {
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	ids, err := s.deltaIDs(saveName)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	if len(ids) > 0 {
+		saveData, _, deltas, err := s.readSaveAndDeltas(context.Background(), saveName)
+		if err == nil {
+			saveData, err = s.applyDeltas(saveData, deltas)
+		}
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(sum, saveData)
+		return hex.EncodeToString(sum.Sum(nil)), nil
+	}
+	r, _, err := s.openSave(saveName)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	if _, err := io.Copy(sum, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
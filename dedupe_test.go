@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "Farm", `{"day":1}`)
+	mustSave(t, s, "Farm copy", `{"day":1}`)
+	mustSave(t, s, "Other", `{"day":2}`)
+	groups, err := s.FindDuplicates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("FindDuplicates = %v, want one group", groups)
+	}
+	for _, group := range groups {
+		if !slices.Equal(group, []string{"Farm", "Farm copy"}) {
+			t.Errorf("duplicate group %v, want [Farm Farm copy]", group)
+		}
+	}
+}
+
+func TestDeduplicateSaves(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "Farm", `{"day":1}`)
+	mustSave(t, s, "Farm copy", `{"day":1}`)
+	mustSave(t, s, "Other", `{"day":2}`)
+	if err := s.DeduplicateSaves("Farm", []string{"Other"}); !errors.Is(err, ErrNotDuplicate) {
+		t.Errorf("DeduplicateSaves of a different save = %v, want ErrNotDuplicate", err)
+	}
+	if err := s.SetLastSave("Farm copy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeduplicateSaves("Farm", []string{"Farm copy"}); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := s.GetAllSaves(); err != nil || !slices.Equal(names, []string{"Farm", "Other"}) {
+		t.Errorf("GetAllSaves = %v, %v, want [Farm Other]", names, err)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "Farm" {
+		t.Errorf("GetLastSave = %q, %v, want the kept save", last, err)
+	}
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// defaultDeltaCompaction is how many deltas SaveDelta stacks on a full save
+// before writing a fresh full save instead.
+const defaultDeltaCompaction = 10
+
+// WithDeltaCompaction sets how many deltas SaveDelta stores on top of a full
+// save before it folds them into a fresh full save.
+func WithDeltaCompaction(n int) Option {
+	return func(s *SaveManager) {
+		s.deltaCompaction = n
+	}
+}
+
+func (s *SaveManager) deltaDir(saveName string) string {
+	return filepath.Join(s.dir(), "deltas", saveName)
+}
+
+// deltaRecord is one stored delta: a JSON merge patch (RFC 7396) together
+// with the hashes of the states it applies to and produces.
+type deltaRecord struct {
+	Base  string          `json:"base"`
+	State string          `json:"state"`
+	Patch json.RawMessage `json:"patch"`
+}
+
+// SaveDelta saves newSave as a delta against baseSave, the state last saved
+// to saveName, which is much cheaper than SaveGame for large saves that
+// change little. LoadGame applies the deltas transparently. When baseSave is
+// not the saved state, the change can't be expressed as a delta, or the
+// configured number of deltas has been reached, newSave is written in full
+// instead. Saves kept in a Store set with WithStore are always written in
+// full.
+func (s *SaveManager) SaveDelta(saveName string, baseSave string, newSave string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	base, err := decodeState(baseSave)
+	if err != nil {
+		return fmt.Errorf("save delta %q: %w", saveName, err)
+	}
+	next, err := decodeState(newSave)
+	if err != nil {
+		return fmt.Errorf("save delta %q: %w", saveName, err)
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	err = s.saveDelta(saveName, base, next, newSave)
+	mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("save delta %q: %w", saveName, err)
+	}
+	s.notifySave(saveName, int64(len(newSave)))
+	return nil
+}
+
+func (s *SaveManager) saveDelta(saveName string, base, next any, newSave string) error {
+	if !s.localStore() {
+		saveData, err := s.formatSaveData(newSave)
+		if err != nil {
+			return err
+		}
+		if err := s.store.Save(context.Background(), saveName, saveData); err != nil {
+			return err
+		}
+		return s.makePermanent(saveName)
+	}
+	if err := s.checkNotReadOnly(saveName); err != nil {
+		return err
+	}
+	release, err := s.holdFileLock(saveName)
+	if err != nil {
+		return err
+	}
+	written, err := s.tryWriteDelta(saveName, base, next, newSave)
+	// saveGame takes the OS lock itself.
+	release()
+	if err != nil {
+		return err
+	}
+	if !written {
+		saveData, err := s.formatSaveData(newSave)
+		if err != nil {
+			return err
+		}
+		if err := s.saveGame(context.Background(), saveName, saveData); err != nil {
+			return err
+		}
+	}
+	return s.makePermanent(saveName)
+}
+
+// tryWriteDelta writes next as a delta on top of saveName if it can be
+// expressed as one, and reports whether it did. The caller must hold the
+// save's write lock and its OS lock.
+func (s *SaveManager) tryWriteDelta(saveName string, base, next any, newSave string) (bool, error) {
+	ids, err := s.deltaIDs(saveName)
+	if err != nil {
+		return false, err
+	}
+	if len(ids) >= s.deltaCompaction {
+		return false, nil
+	}
+	baseHash, err := stateHash(base)
+	if err != nil {
+		return false, err
+	}
+	current, err := s.currentStateHash(saveName, ids)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	patch, ok := mergePatch(base, next)
+	if !ok || current != baseHash {
+		return false, nil
+	}
+	if err := s.writeDelta(saveName, len(ids)+1, baseHash, next, patch); err != nil {
+		return false, err
+	}
+	if err := s.stampGameVersion(saveName); err != nil {
+		return false, err
+	}
+	if err := s.bumpGeneration(saveName); err != nil {
+		return false, err
+	}
+	s.recordHistory(saveName, int64(len(newSave)), "")
+	return true, nil
+}
+
+// currentStateHash returns the hash of the state saveName holds, given its
+// delta IDs.
+func (s *SaveManager) currentStateHash(saveName string, ids []int) (string, error) {
+	if len(ids) > 0 {
+		rec, err := s.readDelta(saveName, ids[len(ids)-1])
+		if err != nil {
+			return "", err
+		}
+		return rec.State, nil
+	}
+	saveData, _, err := s.readSave(context.Background(), saveName)
+	if err != nil {
+		return "", err
+	}
+	state, err := decodeState(saveData)
+	if err != nil {
+		return "", err
+	}
+	return stateHash(state)
+}
+
+func (s *SaveManager) writeDelta(saveName string, id int, baseHash string, next, patch any) error {
+	s.uncacheSave(saveName)
+	state, err := stateHash(next)
+	if err != nil {
+		return err
+	}
+	rawPatch, err := encodeState(patch)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(deltaRecord{Base: baseHash, State: state, Patch: rawPatch})
+	if err != nil {
+		return err
+	}
+	ext := s.jsonExt
+	switch {
+	case s.passphrase != "":
+		data, err = s.sealSave(data)
+	case s.compress:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err = zw.Write(data); err == nil {
+			err = zw.Close()
+		}
+		data = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+	if s.compress {
+		ext = s.jsonExt + gzExt
+	}
+	dir := s.deltaDir(saveName)
+	if err := s.fs.MkdirAll(dir, s.dirMode); err != nil {
+		return err
+	}
+	return s.writeFileAtomic(filepath.Join(dir, fmt.Sprintf("%06d", id)+ext), s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// deltaIDs returns the IDs of the deltas stored for saveName, in order.
+func (s *SaveManager) deltaIDs(saveName string) ([]int, error) {
+	files, err := s.fs.ReadDir(s.deltaDir(saveName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, file := range files {
+		name, ok := s.trimSaveExt(file.Name())
+		if !ok {
+			continue
+		}
+		var id int
+		if _, err := fmt.Sscanf(name, "%06d", &id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (s *SaveManager) readDelta(saveName string, id int) (deltaRecord, error) {
+	var rec deltaRecord
+	filename := filepath.Join(s.deltaDir(saveName), fmt.Sprintf("%06d", id)+s.jsonExt+gzExt)
+	data, err := readFile(s.fs, filename)
+	if errors.Is(err, os.ErrNotExist) {
+		filename = strings.TrimSuffix(filename, gzExt)
+		data, err = readFile(s.fs, filename)
+	}
+	if err != nil {
+		return rec, err
+	}
+	r, err := s.decodeFile(filename, data)
+	if err != nil {
+		return rec, err
+	}
+	err = json.NewDecoder(r).Decode(&rec)
+	return rec, err
+}
+
+// readDeltas returns every delta stored for saveName, in order. The caller
+// must hold the save's lock.
+func (s *SaveManager) readDeltas(saveName string) ([]deltaRecord, error) {
+	ids, err := s.deltaIDs(saveName)
+	if err != nil {
+		return nil, err
+	}
+	deltas := make([]deltaRecord, 0, len(ids))
+	for _, id := range ids {
+		rec, err := s.readDelta(saveName, id)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, rec)
+	}
+	return deltas, nil
+}
+
+// applyDeltas returns saveData with deltas applied. Deltas that don't start
+// from saveData are left over from a full save that was interrupted before
+// it could remove them, and are ignored.
+func (s *SaveManager) applyDeltas(saveData string, deltas []deltaRecord) (string, error) {
+	if len(deltas) == 0 {
+		return saveData, nil
+	}
+	state, err := decodeState(saveData)
+	if err != nil {
+		return "", err
+	}
+	if hash, err := stateHash(state); err != nil || hash != deltas[0].Base {
+		return saveData, err
+	}
+	for _, rec := range deltas {
+		patch, err := decodeState(string(rec.Patch))
+		if err != nil {
+			return "", err
+		}
+		state = applyPatch(state, patch)
+	}
+	if hash, err := stateHash(state); err != nil {
+		return "", err
+	} else if hash != deltas[len(deltas)-1].State {
+		return "", ErrChecksumMismatch
+	}
+	data, err := encodeState(state)
+	if err != nil {
+		return "", err
+	}
+	return s.formatSaveData(string(data))
+}
+
+// decodeState parses saveData, keeping numbers exactly as written.
+func decodeState(saveData string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(saveData))
+	dec.UseNumber()
+	var state any
+	if err := dec.Decode(&state); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSaveData, err)
+	}
+	if dec.More() {
+		return nil, ErrInvalidSaveData
+	}
+	return state, nil
+}
+
+// encodeState is the inverse of decodeState. Object keys come out sorted, so
+// equal states always encode the same.
+func encodeState(state any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(state); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func stateHash(state any) (string, error) {
+	data, err := encodeState(state)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// mergePatch returns the JSON merge patch that turns base into next. It
+// reports false if no merge patch can, since merge patches cannot set a
+// value to null.
+func mergePatch(base, next any) (any, bool) {
+	patch := diffState(base, next)
+	return patch, reflect.DeepEqual(applyPatch(base, patch), next)
+}
+
+func diffState(base, next any) any {
+	bm, ok := base.(map[string]any)
+	nm, ok2 := next.(map[string]any)
+	if !ok || !ok2 {
+		return next
+	}
+	patch := make(map[string]any)
+	for k := range bm {
+		if _, ok := nm[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, nv := range nm {
+		bv, ok := bm[k]
+		if !ok {
+			patch[k] = nv
+		} else if !reflect.DeepEqual(bv, nv) {
+			patch[k] = diffState(bv, nv)
+		}
+	}
+	return patch
+}
+
+// applyPatch applies a JSON merge patch to target without modifying it.
+func applyPatch(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	tm, _ := target.(map[string]any)
+	out := make(map[string]any, len(tm)+len(pm))
+	for k, v := range tm {
+		out[k] = v
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(out, k)
+		} else {
+			out[k] = applyPatch(out[k], v)
+		}
+	}
+	return out
+}
+
+// copySaveDir copies the files in the directory dir gives for srcName, such
+// as its deltas, to the one for dstName, replacing any there.
+func (s *SaveManager) copySaveDir(dir func(string) string, srcName, dstName string) error {
+	if err := s.fs.RemoveAll(dir(dstName)); err != nil {
+		return err
+	}
+	files, err := s.fs.ReadDir(dir(srcName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.fs.MkdirAll(dir(dstName), s.dirMode); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || strings.HasSuffix(file.Name(), tmpExt) {
+			continue
+		}
+		src := filepath.Join(dir(srcName), file.Name())
+		if err := s.copyFileAtomic(src, filepath.Join(dir(dstName), file.Name()), s.fileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
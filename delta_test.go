@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sameJSON reports whether a and b hold the same JSON value.
+func sameJSON(t *testing.T, a, b string) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		t.Fatalf("%s: %v", a, err)
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		t.Fatalf("%s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func TestLoadGameAppliesDeltas(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"plain", nil},
+		{"compressed", []Option{WithCompression(true)}},
+		{"encrypted", []Option{WithEncryption("secret")}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _ := newTestManager(t, append(tc.opts, WithDeltaCompaction(10))...)
+			cur := `{"day":1,"inventory":{"seeds":3,"hoe":1},"gold":100}`
+			mustSave(t, s, "farm", cur)
+			for _, next := range []string{
+				`{"day":2,"inventory":{"seeds":3,"hoe":1},"gold":100}`,
+				`{"day":3,"inventory":{"seeds":1},"gold":140,"crops":["parsnip"]}`,
+				`{"day":4,"inventory":{"seeds":1},"gold":140,"crops":["parsnip","kale"]}`,
+			} {
+				if err := s.SaveDelta("farm", cur, next); err != nil {
+					t.Fatal(err)
+				}
+				cur = next
+				if got := mustLoad(t, s, "farm"); !sameJSON(t, got, cur) {
+					t.Errorf("LoadGame = %s, want %s", got, cur)
+				}
+			}
+			if ids, err := s.deltaIDs("farm"); err != nil || len(ids) != 3 {
+				t.Errorf("deltaIDs = %v, %v, want 3 deltas", ids, err)
+			}
+		})
+	}
+}
+
+func TestSaveDeltaCompacts(t *testing.T) {
+	s, _ := newTestManager(t, WithDeltaCompaction(3))
+	saves := []string{`{"day":1}`, `{"day":2}`, `{"day":3}`, `{"day":4}`, `{"day":5}`}
+	mustSave(t, s, "farm", saves[0])
+	for i := 1; i < len(saves); i++ {
+		if err := s.SaveDelta("farm", saves[i-1], saves[i]); err != nil {
+			t.Fatal(err)
+		}
+		ids, err := s.deltaIDs("farm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The fourth delta compacts the three before it into a full save.
+		if want := i % 4; len(ids) != want {
+			t.Errorf("after %d deltas: %d stored, want %d", i, len(ids), want)
+		}
+	}
+	if got := mustLoad(t, s, "farm"); got != saves[len(saves)-1] {
+		t.Errorf("LoadGame = %s", got)
+	}
+}
+
+func TestSaveDeltaWithStaleBaseSavesInFull(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	if err := s.SaveDelta("farm", `{"day":99}`, `{"day":2}`); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := s.deltaIDs("farm"); err != nil || len(ids) != 0 {
+		t.Errorf("deltaIDs = %v, %v, want a full save", ids, err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+}
+
+func TestSaveDeltaLocked(t *testing.T) {
+	s, dir := newTestManager(t, WithFileLocks(true))
+	mustSave(t, s, "farm", `{"day":1}`)
+	other, err := NewSaveManagerWithDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.LockSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	defer other.UnlockSave("farm")
+	if err := s.SaveDelta("farm", `{"day":1}`, `{"day":2}`); !errors.Is(err, ErrSaveLocked) {
+		t.Errorf("SaveDelta = %v, want ErrSaveLocked", err)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DiffOp is the kind of change a DiffEntry describes.
+type DiffOp string
+
+const (
+	DiffAdded   DiffOp = "added"
+	DiffRemoved DiffOp = "removed"
+	DiffChanged DiffOp = "changed"
+)
+
+// DiffEntry is one difference between two saves. Path is a JSON Pointer
+// (RFC 6901) to the field, such as "/player/gold". Old and New hold the JSON
+// encoding of the value before and after, and are empty for added and
+// removed fields respectively.
+type DiffEntry struct {
+	Path string `json:"path"`
+	Op   DiffOp `json:"op"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// String formats e as a line of a diff, such as "~ /gold: 10 -> 25".
+func (e DiffEntry) String() string {
+	switch e.Op {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", e.Path, e.New)
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", e.Path, e.Old)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", e.Path, e.Old, e.New)
+}
+
+// DiffSaves compares the JSON of nameA against nameB field by field and
+// returns what changed going from A to B. Objects are compared key by key, in
+// key order, and arrays index by index; any other change is reported on the
+// value as a whole. An error wrapping ErrInvalidSaveData is returned if
+// either save is not JSON.
+func (s *SaveManager) DiffSaves(nameA, nameB string) ([]DiffEntry, error) {
+	if err := validateSaveName(nameA); err != nil {
+		return nil, err
+	}
+	if err := validateSaveName(nameB); err != nil {
+		return nil, err
+	}
+	a, err := s.loadDiffState(nameA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.loadDiffState(nameB)
+	if err != nil {
+		return nil, err
+	}
+	diff := []DiffEntry{}
+	if err := diffValues(&diff, "", a, b); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+func (s *SaveManager) loadDiffState(saveName string) (any, error) {
+	saveData, err := s.loadGame(context.Background(), saveName)
+	if err != nil {
+		return nil, fmt.Errorf("diff save %q: %w", saveName, err)
+	}
+	state, err := decodeState(saveData)
+	if err != nil {
+		return nil, fmt.Errorf("diff save %q: %w", saveName, err)
+	}
+	return state, nil
+}
+
+// diffValues appends the differences between a and b, found at path, to diff.
+func diffValues(diff *[]DiffEntry, path string, a, b any) error {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			keys := slices.Collect(maps.Keys(a))
+			for k := range b {
+				if _, ok := a[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				p := path + "/" + escapePointer(k)
+				av, inA := a[k]
+				bv, inB := b[k]
+				var err error
+				switch {
+				case !inB:
+					err = appendDiff(diff, p, DiffRemoved, av, nil)
+				case !inA:
+					err = appendDiff(diff, p, DiffAdded, nil, bv)
+				default:
+					err = diffValues(diff, p, av, bv)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < max(len(a), len(b)); i++ {
+				p := path + "/" + strconv.Itoa(i)
+				var err error
+				switch {
+				case i >= len(b):
+					err = appendDiff(diff, p, DiffRemoved, a[i], nil)
+				case i >= len(a):
+					err = appendDiff(diff, p, DiffAdded, nil, b[i])
+				default:
+					err = diffValues(diff, p, a[i], b[i])
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return appendDiff(diff, path, DiffChanged, a, b)
+}
+
+func appendDiff(diff *[]DiffEntry, path string, op DiffOp, oldValue, newValue any) error {
+	entry := DiffEntry{Path: path, Op: op}
+	if op != DiffAdded {
+		data, err := encodeState(oldValue)
+		if err != nil {
+			return err
+		}
+		entry.Old = string(data)
+	}
+	if op != DiffRemoved {
+		data, err := encodeState(newValue)
+		if err != nil {
+			return err
+		}
+		entry.New = string(data)
+	}
+	*diff = append(*diff, entry)
+	return nil
+}
+
+// escapePointer escapes key for use as a JSON Pointer reference token.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDiffSaves(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "before", `{"gold":10,"pet":"cat","crops":["kale","corn"],"paths":{"a/b":1}}`)
+	mustSave(t, s, "after", `{"gold":25,"crops":["kale"],"horse":"Rusty","paths":{"a/b":2}}`)
+	diff, err := s.DiffSaves("before", "after")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Path: "/crops/1", Op: DiffRemoved, Old: `"corn"`},
+		{Path: "/gold", Op: DiffChanged, Old: "10", New: "25"},
+		{Path: "/horse", Op: DiffAdded, New: `"Rusty"`},
+		{Path: "/paths/a~1b", Op: DiffChanged, Old: "1", New: "2"},
+		{Path: "/pet", Op: DiffRemoved, Old: `"cat"`},
+	}
+	if !slices.Equal(diff, want) {
+		t.Errorf("DiffSaves =\n%v\nwant\n%v", diff, want)
+	}
+	if got := want[1].String(); got != "~ /gold: 10 -> 25" {
+		t.Errorf("String = %q", got)
+	}
+
+	same, err := s.DiffSaves("before", "before")
+	if err != nil || same == nil || len(same) != 0 {
+		t.Errorf("DiffSaves of a save with itself = %v, %v, want empty", same, err)
+	}
+}
+
+func TestDiffSavesMissingOrInvalid(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.SaveGameRaw("notes", "not json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DiffSaves("farm", "missing"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("DiffSaves with a missing save = %v, want ErrSaveNotFound", err)
+	}
+	if _, err := s.DiffSaves("notes", "farm"); !errors.Is(err, ErrInvalidSaveData) {
+		t.Errorf("DiffSaves with a non-JSON save = %v, want ErrInvalidSaveData", err)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// spaceMargin is the room CanSave leaves on top of the estimated save size
+// for backups, sidecars and other programs.
+const spaceMargin = 16 << 20
+
+// ErrInsufficientSpace is returned when the disk holding the data directory
+// is too full to save.
+var ErrInsufficientSpace = errors.New("not enough disk space")
+
+// ErrSaveTooLarge is returned when a save exceeds the WithMaxSaveSize limit.
+var ErrSaveTooLarge = errors.New("save too large")
+
+// WithMaxSaveSize makes saving fail with ErrSaveTooLarge, leaving the
+// previous save in place, when the save data or the file written for it
+// would exceed n bytes, so that a runaway game state cannot fill the disk.
+// Streamed saves are counted as they are read.
+func WithMaxSaveSize(n int64) Option {
+	return func(s *SaveManager) {
+		s.maxSaveSize = n
+	}
+}
+
+// checkSaveSize reports ErrSaveTooLarge if size exceeds the save size limit.
+func (s *SaveManager) checkSaveSize(size int64) error {
+	if s.maxSaveSize > 0 && size > s.maxSaveSize {
+		return fmt.Errorf("%w: over %d bytes", ErrSaveTooLarge, s.maxSaveSize)
+	}
+	return nil
+}
+
+// sizeLimitedReader fails with ErrSaveTooLarge once more than the save size
+// limit has been read through it.
+type sizeLimitedReader struct {
+	s *SaveManager
+	r io.Reader
+	n int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if err := l.s.checkSaveSize(l.n); err != nil {
+		return n, err
+	}
+	return n, err
+}
+
+// sizeLimitedWriter fails with ErrSaveTooLarge instead of writing past the
+// save size limit.
+type sizeLimitedWriter struct {
+	s *SaveManager
+	w io.Writer
+	n int64
+}
+
+func (l *sizeLimitedWriter) Write(p []byte) (int, error) {
+	if err := l.s.checkSaveSize(l.n + int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := l.w.Write(p)
+	l.n += int64(n)
+	return n, err
+}
+
+// CanSave reports whether the disk holding the data directory has room for a
+// save of about estimatedBytes, so the game can warn the player before
+// saving instead of failing midway.
+func (s *SaveManager) CanSave(saveName string, estimatedBytes int64) (bool, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return false, err
+	}
+	if estimatedBytes < 0 {
+		return false, fmt.Errorf("check space for save %q: negative size %d", saveName, estimatedBytes)
+	}
+	free, err := availableSpace(s.dir())
+	if err != nil {
+		return false, fmt.Errorf("check space for save %q: %w", saveName, err)
+	}
+	return free >= uint64(estimatedBytes)+spaceMargin, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCanSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	if ok, err := s.CanSave("farm", 1024); err != nil || !ok {
+		t.Errorf("CanSave(1 KiB) = %v, %v, want true", ok, err)
+	}
+	if ok, err := s.CanSave("farm", 1<<62); err != nil || ok {
+		t.Errorf("CanSave(4 EiB) = %v, %v, want false", ok, err)
+	}
+	if _, err := s.CanSave("farm", -1); err == nil {
+		t.Error("CanSave accepted a negative size")
+	}
+}
+
+func TestMaxSaveSize(t *testing.T) {
+	s, dir := newTestManager(t, WithMaxSaveSize(20))
+	mustSave(t, s, "farm", `{"day":1}`)
+
+	tooLarge := `{"notes":"` + strings.Repeat("x", 30) + `"}`
+	if err := s.SaveGame("farm", tooLarge); !errors.Is(err, ErrSaveTooLarge) {
+		t.Errorf("SaveGame over the limit = %v, want ErrSaveTooLarge", err)
+	}
+	streamed := strings.NewReader(`{"notes":"` + strings.Repeat("x", 100000) + `"}`)
+	if err := s.SaveGameReader("farm", streamed); !errors.Is(err, ErrSaveTooLarge) {
+		t.Errorf("SaveGameReader over the limit = %v, want ErrSaveTooLarge", err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s, want the save under the limit", got)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "*"+tmpExt)); len(tmps) != 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+}
+
+func TestMaxSaveSizeCountsFileWritten(t *testing.T) {
+	// Encryption adds a nonce and tag, pushing the file over the limit.
+	s, _ := newTestManager(t, WithMaxSaveSize(30), WithEncryption("secret"))
+	if err := s.SaveGame("farm", `{"day":1}`); !errors.Is(err, ErrSaveTooLarge) {
+		t.Errorf("SaveGame = %v, want ErrSaveTooLarge", err)
+	}
+}
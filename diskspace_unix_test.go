@@ -0,0 +1,27 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+)
+
+func TestSaveGameDiskFull(t *testing.T) {
+	mem := newMemFS()
+	s, err := NewSaveManagerWithDir("/data", WithFileSystem(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+	mem.writeErr = &fs.PathError{Op: "write", Path: "/data/farm.json.tmp", Err: syscall.ENOSPC}
+	if err := s.SaveGame("farm", `{"day":2}`); !errors.Is(err, ErrInsufficientSpace) {
+		t.Fatalf("SaveGame on a full disk = %v, want ErrInsufficientSpace", err)
+	}
+	mem.writeErr = nil
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s, want the previous save", got)
+	}
+}
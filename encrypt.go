@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrDecryptionFailed is returned when an encrypted save cannot be decrypted,
+// usually because the passphrase is wrong or missing.
+var ErrDecryptionFailed = errors.New("save decryption failed")
+
+// encryptedMagic starts every encrypted save, followed by the scrypt salt,
+// the AES-GCM nonce and the ciphertext.
+var encryptedMagic = []byte("VLENC1")
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// WithEncryption encrypts saves with AES-256-GCM under a key derived from
+// passphrase. Unencrypted saves still load.
+func WithEncryption(passphrase string) Option {
+	return func(s *SaveManager) {
+		s.passphrase = passphrase
+	}
+}
+
+// keyCache remembers keys derived for each salt, since scrypt is
+// deliberately slow and autosaves would otherwise pay for it every time.
+type keyCache struct {
+	mu      sync.Mutex
+	salt    []byte
+	derived map[string][]byte
+}
+
+func (s *SaveManager) key(salt []byte) ([]byte, error) {
+	s.keys.mu.Lock()
+	defer s.keys.mu.Unlock()
+	if key, ok := s.keys.derived[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, err
+	}
+	if s.keys.derived == nil {
+		s.keys.derived = make(map[string][]byte)
+	}
+	s.keys.derived[string(salt)] = key
+	return key, nil
+}
+
+// writeSalt returns the salt used for new saves, picked once per manager.
+func (s *SaveManager) writeSalt() ([]byte, error) {
+	s.keys.mu.Lock()
+	defer s.keys.mu.Unlock()
+	if s.keys.salt == nil {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		s.keys.salt = salt
+	}
+	return s.keys.salt, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *SaveManager) encrypt(plaintext []byte) ([]byte, error) {
+	salt, err := s.writeSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.key(salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(encryptedMagic)+saltSize+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, encryptedMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, encryptedMagic), nil
+}
+
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+func (s *SaveManager) decrypt(data []byte) ([]byte, error) {
+	if s.passphrase == "" {
+		return nil, fmt.Errorf("%w: save is encrypted and no passphrase is set", ErrDecryptionFailed)
+	}
+	data = data[len(encryptedMagic):]
+	if len(data) < saltSize {
+		return nil, ErrDecryptionFailed
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+	key, err := s.key(salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, encryptedMagic)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// expiryExt is the extension of the sidecar file holding when a temporary
+// save expires.
+const expiryExt = ".expires"
+
+// SaveGameTemporary saves saveData like SaveGame and marks the save as
+// temporary, to be removed by PurgeExpired once ttl has passed. Saving over
+// it again with SaveGame or its variants makes it permanent, except for
+// UpdateSave, which edits the save in place; SaveGameTemporary sets a new
+// expiry.
+func (s *SaveManager) SaveGameTemporary(saveName, saveData string, ttl time.Duration) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	saveData, err := s.formatSaveData(saveData)
+	if err != nil {
+		return fmt.Errorf("save %q: %w", saveName, err)
+	}
+	expiresAt := s.now().Add(ttl).UTC().Format(time.RFC3339Nano)
+	if err := s.saveWithSidecar(saveName, saveData, expiryExt, []byte(expiresAt)); err != nil {
+		return err
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return nil
+}
+
+// PurgeExpired deletes every temporary save whose expiry has passed, like
+// DeleteSave, and returns how many it deleted. Saves written with SaveGame
+// are never purged, nor are temporary saves marked read-only. It carries on
+// past failures and returns an error joining them, if any.
+func (s *SaveManager) PurgeExpired() (int, error) {
+	names, err := s.store.List()
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	purged := 0
+	var errs []error
+	for _, saveName := range names {
+		expiresAt, ok, err := s.readExpiryWithLock(saveName)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge save %q: %w", saveName, err))
+			continue
+		}
+		if !ok || now.Before(expiresAt) {
+			continue
+		}
+		if readOnly, err := s.isReadOnly(saveName); err != nil || readOnly {
+			continue
+		}
+		if err := s.DeleteSave(saveName); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+func (s *SaveManager) readExpiryWithLock(saveName string) (time.Time, bool, error) {
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	return s.readExpiry(saveName)
+}
+
+// readExpiry returns the time the temporary save saveName expires, and false
+// if it is not temporary.
+func (s *SaveManager) readExpiry(saveName string) (time.Time, bool, error) {
+	data, err := s.loadSidecar(saveName, expiryExt)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	expiresAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("read expiry: %w", err)
+	}
+	return expiresAt, true, nil
+}
+
+// makePermanent removes the expiry of saveName, if it is temporary. The
+// caller must hold the save's write lock.
+func (s *SaveManager) makePermanent(saveName string) error {
+	if s.localStore() {
+		err := s.fs.Remove(filepath.Join(s.dir(), saveName+expiryExt))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if sc, ok := s.store.(SidecarStore); ok {
+		return sc.DeleteSidecar(saveName, expiryExt)
+	}
+	return nil
+}
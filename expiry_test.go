@@ -0,0 +1,99 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPurgeExpired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s, _ := newTestManager(t, WithClock(func() time.Time { return now }))
+	mustSave(t, s, "farm", `{"day":1}`)
+	for saveName, ttl := range map[string]time.Duration{
+		"debug old": time.Hour,
+		"debug new": 3 * time.Hour,
+		"pinned":    time.Minute,
+	} {
+		if err := s.SaveGameTemporary(saveName, `{"day":1}`, ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SetReadOnly("pinned", true); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := s.StatSave("debug old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Temporary || !info.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("StatSave = %+v, want temporary until %v", info, now.Add(time.Hour))
+	}
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if info.Temporary != (info.Name != "farm") {
+			t.Errorf("save %q Temporary = %v", info.Name, info.Temporary)
+		}
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n, err := s.PurgeExpired(); err != nil || n != 1 {
+		t.Fatalf("PurgeExpired = %d, %v, want 1", n, err)
+	}
+	names, err := s.GetAllSaves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"debug new", "farm", "pinned"}) {
+		t.Errorf("saves after purge = %v, want the expired one gone", names)
+	}
+}
+
+func TestSaveGameMakesTemporarySavePermanent(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s, dir := newTestManager(t, WithClock(func() time.Time { return now }))
+	saves := map[string]func(saveName string) error{
+		"plain": func(saveName string) error { return s.SaveGame(saveName, `{"day":2}`) },
+		"raw":   func(saveName string) error { return s.SaveGameRaw(saveName, `{"day":2}`) },
+		"reader": func(saveName string) error {
+			return s.SaveGameReader(saveName, strings.NewReader(`{"day":2}`))
+		},
+		"delta": func(saveName string) error { return s.SaveDelta(saveName, `{"day":1}`, `{"day":2}`) },
+	}
+	for saveName, save := range saves {
+		if err := s.SaveGameTemporary(saveName, `{"day":1}`, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if err := save(saveName); err != nil {
+			t.Fatalf("saving over %q: %v", saveName, err)
+		}
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n, err := s.PurgeExpired(); err != nil || n != 0 {
+		t.Errorf("PurgeExpired = %d, %v, want 0", n, err)
+	}
+	for saveName := range saves {
+		if got := mustLoad(t, s, saveName); got != `{"day":2}` {
+			t.Errorf("LoadGame(%q) = %s, want the second save", saveName, got)
+		}
+		info, err := s.StatSave(saveName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Temporary {
+			t.Errorf("save %q still temporary after saving over it", saveName)
+		}
+	}
+	for _, name := range dirFiles(t, dir) {
+		if strings.HasSuffix(name, expiryExt) {
+			t.Errorf("expiry file %s left behind", name)
+		}
+	}
+}
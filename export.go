@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces the values removed by ExportRedacted.
+const redactedValue = "[redacted]"
+
+// ExportSave writes saveName as plain JSON to destPath, outside the data
+// directory, so it can be shared or backed up by hand. The save is migrated
+// to the current schema version first.
+func (s *SaveManager) ExportSave(saveName string, destPath string) error {
+	saveData, err := s.LoadGame(saveName)
+	if err != nil {
+		return err
+	}
+	return s.writeFileAtomic(destPath, s.fileMode, func(w io.Writer) error {
+		_, err := io.WriteString(w, saveData)
+		return err
+	})
+}
+
+// ExportRedacted is ExportSave with the values at redactKeys replaced by
+// "[redacted]", for saves attached to bug reports. Each key is a dotted path
+// as taken by LoadField; keys the save does not have are ignored. The save
+// itself is left as it is.
+func (s *SaveManager) ExportRedacted(saveName string, redactKeys []string, destPath string) error {
+	saveData, err := s.LoadGame(saveName)
+	if err != nil {
+		return err
+	}
+	state, err := decodeState(saveData)
+	if err != nil {
+		return fmt.Errorf("export save %q: %w", saveName, err)
+	}
+	for _, key := range redactKeys {
+		if key != "" {
+			redact(state, strings.Split(key, "."))
+		}
+	}
+	data, err := encodeState(state)
+	if err != nil {
+		return fmt.Errorf("export save %q: %w", saveName, err)
+	}
+	return s.writeFileAtomic(destPath, s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// redact replaces the value at path in state, if there is one.
+func redact(state any, path []string) {
+	switch v := state.(type) {
+	case map[string]any:
+		value, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = redactedValue
+			return
+		}
+		redact(value, path[1:])
+	case []any:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(v) {
+			return
+		}
+		if len(path) == 1 {
+			v[i] = redactedValue
+			return
+		}
+		redact(v[i], path[1:])
+	}
+}
+
+// ImportSave copies the JSON file at srcPath into the data directory as
+// saveName. It refuses to replace an existing save unless overwrite is set.
+// The data is taken to be at the current schema version, as ExportSave
+// writes it.
+func (s *SaveManager) ImportSave(srcPath string, saveName string, overwrite bool) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	data, err := readFile(s.fs, srcPath)
+	if err != nil {
+		return fmt.Errorf("import save %q: %w", saveName, err)
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("import save %q: %s: %w", saveName, srcPath, ErrInvalidSaveData)
+	}
+	return s.importSave(saveName, data, overwrite)
+}
+
+// importSave writes the JSON data as saveName, formatted and reported to the
+// OnSave hooks like SaveGame, unless saveName exists and overwrite is unset.
+func (s *SaveManager) importSave(saveName string, data []byte, overwrite bool) error {
+	saveData, err := s.formatSaveData(string(data))
+	if err != nil {
+		return fmt.Errorf("import save %q: %w", saveName, err)
+	}
+	if err := s.writeImport(saveName, saveData, overwrite); err != nil {
+		return err
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return nil
+}
+
+func (s *SaveManager) writeImport(saveName, saveData string, overwrite bool) error {
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	if !overwrite {
+		if _, err := s.savePath(saveName); err == nil {
+			return fmt.Errorf("import save %q: %w", saveName, ErrSaveExists)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return s.saveGame(context.Background(), saveName, saveData)
+}
+
+// ImportLegacyDir imports the saves of an older version of the game kept in
+// srcDir. Each file under srcDir, other than temporary and lock files, is
+// passed to transform with its path relative to srcDir, which returns the
+// name and JSON data to import it as, or skip to leave it out. Existing saves
+// are never replaced. A file that fails, in transform or in the import, does
+// not stop the others: ImportLegacyDir returns the saves imported and an
+// error joining the failures, if any.
+func (s *SaveManager) ImportLegacyDir(srcDir string, transform func(filename string, data []byte) (newName string, newData []byte, skip bool, err error)) (imported []string, err error) {
+	files, err := s.dataFiles(srcDir, "")
+	if err != nil {
+		return nil, fmt.Errorf("import legacy saves from %s: %w", srcDir, err)
+	}
+	imported = []string{}
+	var errs []error
+	for _, rel := range files {
+		saveName, err := s.importLegacyFile(srcDir, rel, transform)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("import legacy save %s: %w", rel, err))
+			continue
+		}
+		if saveName != "" {
+			imported = append(imported, saveName)
+		}
+	}
+	return imported, errors.Join(errs...)
+}
+
+// importLegacyFile imports the file rel under srcDir as transform says,
+// returning the name it was imported as or "" if it was skipped.
+func (s *SaveManager) importLegacyFile(srcDir, rel string, transform func(string, []byte) (string, []byte, bool, error)) (string, error) {
+	data, err := readFile(s.fs, filepath.Join(srcDir, rel))
+	if err != nil {
+		return "", err
+	}
+	saveName, saveData, skip, err := transform(rel, data)
+	if err != nil || skip {
+		return "", err
+	}
+	if err := validateSaveName(saveName); err != nil {
+		return "", err
+	}
+	if !json.Valid(saveData) {
+		return "", ErrInvalidSaveData
+	}
+	if err := s.importSave(saveName, saveData, false); err != nil {
+		return "", err
+	}
+	return saveName, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportSaveRejectsMalformedFile(t *testing.T) {
+	s, _ := newTestManager(t)
+	src := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(src, []byte(`{"day":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ImportSave(src, "farm", false); !errors.Is(err, ErrInvalidSaveData) {
+		t.Fatalf("ImportSave = %v, want ErrInvalidSaveData", err)
+	}
+	if _, err := s.LoadGame("farm"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("malformed import left a save behind: %v", err)
+	}
+}
+
+func TestImportSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	src := filepath.Join(t.TempDir(), "shared.json")
+	if err := os.WriteFile(src, []byte(`{"day":7}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ImportSave(src, "farm", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":7}` {
+		t.Errorf("LoadGame = %s, want the imported save", got)
+	}
+
+	mustSave(t, s, "farm", `{"day":8}`)
+	if err := s.ImportSave(src, "farm", false); !errors.Is(err, ErrSaveExists) {
+		t.Fatalf("ImportSave over an existing save = %v, want ErrSaveExists", err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":8}` {
+		t.Errorf("refused import changed the save to %s", got)
+	}
+	if err := s.ImportSave(src, "farm", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":7}` {
+		t.Errorf("LoadGame after overwrite = %s", got)
+	}
+}
+
+func TestExportSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":7}`)
+	dest := filepath.Join(t.TempDir(), "farm.json")
+	if err := s.ExportSave("farm", dest); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"day":7}` {
+		t.Errorf("exported %s", data)
+	}
+}
+
+func TestExportRedacted(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{"player":{"name":"Ann","hp":3},"caves":[{"x":1},{"x":2}]}`)
+	dest := filepath.Join(t.TempDir(), "report.json")
+	keys := []string{"player.name", "caves.1.x", "horse.name", "player.hp.max"}
+	if err := s.ExportRedacted("farm", keys, dest); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"caves":[{"x":1},{"x":"[redacted]"}],"player":{"hp":3,"name":"[redacted]"}}`
+	if string(data) != want {
+		t.Errorf("exported %s, want %s", data, want)
+	}
+	if got := mustLoad(t, s, "farm"); !strings.Contains(got, `"name":"Ann"`) {
+		t.Errorf("ExportRedacted changed the save to %s", got)
+	}
+}
+
+func TestImportLegacyDir(t *testing.T) {
+	src := t.TempDir()
+	for name, data := range map[string]string{
+		"slot1.sav":     "gold=5",
+		"sub/slot2.sav": "gold=7",
+		"readme.txt":    "not a save",
+		"broken.sav":    "gold",
+	} {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, _ := newTestManager(t)
+	imported, err := s.ImportLegacyDir(src, func(filename string, data []byte) (string, []byte, bool, error) {
+		if filepath.Ext(filename) != ".sav" {
+			return "", nil, true, nil
+		}
+		key, value, ok := strings.Cut(string(data), "=")
+		if !ok {
+			return "", nil, false, errors.New("no value")
+		}
+		newName := strings.TrimSuffix(filepath.Base(filename), ".sav")
+		return newName, []byte(fmt.Sprintf(`{%q:%s}`, key, value)), false, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "broken.sav") {
+		t.Errorf("ImportLegacyDir error = %v, want one naming broken.sav", err)
+	}
+	if !slices.Equal(imported, []string{"slot1", "slot2"}) {
+		t.Errorf("ImportLegacyDir imported %v, want [slot1 slot2]", imported)
+	}
+	if got := mustLoad(t, s, "slot2"); got != `{"gold":7}` {
+		t.Errorf("LoadGame(%q) = %s", "slot2", got)
+	}
+	if names, err := s.GetAllSaves(); err != nil || len(names) != 2 {
+		t.Errorf("GetAllSaves = %v, %v, want only the converted saves", names, err)
+	}
+}
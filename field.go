@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrFieldNotFound is returned by LoadField when the save has no value at
+// the requested path.
+var ErrFieldNotFound = errors.New("field not found in save")
+
+// LoadField returns the value at jsonPath in saveName without decoding the
+// rest of the save, for list screens that only need a field or two. The path
+// is a dotted list of object keys and array indexes, such as "player.name" or
+// "party.0.level". A JSON string is returned unquoted and any other value as
+// JSON. The save is only read as far as the field, so its checksum is not
+// verified.
+func (s *SaveManager) LoadField(saveName string, jsonPath string) (string, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return "", err
+	}
+	var path []string
+	if jsonPath != "" {
+		path = strings.Split(jsonPath, ".")
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	r, version, err := s.openSave(saveName)
+	if err != nil {
+		mu.RUnlock()
+		return "", fmt.Errorf("load field %q of save %q: %w", jsonPath, saveName, err)
+	}
+	ids, err := s.deltaIDs(saveName)
+	if err != nil {
+		r.Close()
+		mu.RUnlock()
+		return "", fmt.Errorf("load field %q of save %q: %w", jsonPath, saveName, err)
+	}
+	if version >= s.currentVersion() && len(ids) == 0 && isJSONCodec(r.codec) {
+		value, err := findField(json.NewDecoder(r), path)
+		r.Close()
+		mu.RUnlock()
+		if err != nil {
+			return "", fmt.Errorf("load field %q of save %q: %w", jsonPath, saveName, err)
+		}
+		return value, nil
+	}
+	r.Close()
+	mu.RUnlock()
+
+	// Migrations, deltas and other formats work on the whole save, so such
+	// saves are loaded in full.
+	saveData, err := s.loadGame(context.Background(), saveName)
+	if err != nil {
+		return "", fmt.Errorf("load field %q of save %q: %w", jsonPath, saveName, err)
+	}
+	value, err := findField(json.NewDecoder(strings.NewReader(saveData)), path)
+	if err != nil {
+		return "", fmt.Errorf("load field %q of save %q: %w", jsonPath, saveName, err)
+	}
+	return value, nil
+}
+
+// findField reads tokens from dec until it reaches the value at path and
+// returns it, skipping over everything else.
+func findField(dec *json.Decoder, path []string) (string, error) {
+	if len(path) == 0 {
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return "", jsonError(err)
+		}
+		var str string
+		if json.Unmarshal(value, &str) == nil {
+			return str, nil
+		}
+		return string(value), nil
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return "", jsonError(err)
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return "", jsonError(err)
+			}
+			if key == path[0] {
+				return findField(dec, path[1:])
+			}
+			if err := skipValue(dec); err != nil {
+				return "", err
+			}
+		}
+	case json.Delim('['):
+		index, err := strconv.Atoi(path[0])
+		if err != nil {
+			return "", ErrFieldNotFound
+		}
+		for i := 0; dec.More(); i++ {
+			if i == index {
+				return findField(dec, path[1:])
+			}
+			if err := skipValue(dec); err != nil {
+				return "", err
+			}
+		}
+	}
+	return "", ErrFieldNotFound
+}
+
+// skipValue reads past the next value in dec without decoding it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return jsonError(err)
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// jsonError marks a decoding error as invalid save data, leaving read errors
+// such as ErrChecksumMismatch as they are.
+func jsonError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %w", ErrInvalidSaveData, err)
+	}
+	return err
+}
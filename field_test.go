@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+const fieldTestSave = `{"world":{"tiles":[1,{"crop":[2,3]}]},"player":{"stats":{"hp":10},"name":"Ann","day":12},"party":[{"level":3},{"level":5}]}`
+
+func TestLoadField(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		s, _ := newTestManager(t, WithCompression(compress))
+		mustSave(t, s, "farm", fieldTestSave)
+		for path, want := range map[string]string{
+			"player.name":   "Ann",
+			"player.day":    "12",
+			"player.stats":  `{"hp":10}`,
+			"party.1.level": "5",
+		} {
+			got, err := s.LoadField("farm", path)
+			if err != nil {
+				t.Errorf("compress %v: LoadField(%q): %v", compress, path, err)
+			} else if got != want {
+				t.Errorf("compress %v: LoadField(%q) = %s, want %s", compress, path, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadFieldNotFound(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", fieldTestSave)
+	for _, path := range []string{"player.age", "party.5", "player.name.first", "horse"} {
+		if _, err := s.LoadField("farm", path); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("LoadField(%q) = %v, want ErrFieldNotFound", path, err)
+		}
+	}
+	if _, err := s.LoadField("missing", "player.name"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadField of a missing save = %v, want ErrSaveNotFound", err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrSaveLocked is returned when another process holds the lock on a save.
+var ErrSaveLocked = errors.New("save is locked by another process")
+
+// WithFileLocks makes every write to a save take its OS lock for the
+// duration of the write, failing with ErrSaveLocked if another process holds
+// it, even if LockSave was never called.
+func WithFileLocks(enabled bool) Option {
+	return func(s *SaveManager) {
+		s.fileLocking = enabled
+	}
+}
+
+func (s *SaveManager) lockFilePath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".lock")
+}
+
+// LockSave takes an advisory OS lock on saveName so that other processes,
+// such as a second copy of the game, cannot write it. It fails with
+// ErrSaveLocked if another process already holds the lock. The lock is held
+// until UnlockSave is called or the process exits. The lock file is left in
+// place afterwards, since removing it would let two processes lock different
+// files.
+func (s *SaveManager) LockSave(saveName string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	s.fileLocksMu.Lock()
+	defer s.fileLocksMu.Unlock()
+	if _, ok := s.fileLocks[saveName]; ok {
+		return nil
+	}
+	f, err := s.acquireFileLock(saveName)
+	if err != nil {
+		return fmt.Errorf("lock save %q: %w", saveName, err)
+	}
+	if s.fileLocks == nil {
+		s.fileLocks = make(map[string]*os.File)
+	}
+	s.fileLocks[saveName] = f
+	return nil
+}
+
+// UnlockSave releases the lock taken by LockSave. Unlocking a save that is
+// not locked does nothing.
+func (s *SaveManager) UnlockSave(saveName string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	s.fileLocksMu.Lock()
+	defer s.fileLocksMu.Unlock()
+	f, ok := s.fileLocks[saveName]
+	if !ok {
+		return nil
+	}
+	delete(s.fileLocks, saveName)
+	if err := releaseFileLock(f); err != nil {
+		return fmt.Errorf("unlock save %q: %w", saveName, err)
+	}
+	return nil
+}
+
+// holdFileLock takes the OS lock on saveName for a write if WithFileLocks is
+// set and LockSave does not already hold it, returning the function that
+// releases it. The caller must hold the save's write lock.
+func (s *SaveManager) holdFileLock(saveName string) (release func(), err error) {
+	if !s.fileLocking {
+		return func() {}, nil
+	}
+	s.fileLocksMu.Lock()
+	defer s.fileLocksMu.Unlock()
+	if _, ok := s.fileLocks[saveName]; ok {
+		return func() {}, nil
+	}
+	f, err := s.acquireFileLock(saveName)
+	if err != nil {
+		return nil, err
+	}
+	return func() { releaseFileLock(f) }, nil
+}
+
+// acquireFileLock opens the lock file of saveName and locks it. The lock
+// files always live on the OS file system, whatever WithFileSystem says.
+func (s *SaveManager) acquireFileLock(saveName string) (*os.File, error) {
+	f, err := os.OpenFile(s.lockFilePath(saveName), os.O_RDWR|os.O_CREATE, s.fileMode)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func releaseFileLock(f *os.File) error {
+	if err := unlockFile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
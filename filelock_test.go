@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLockSaveExcludesOtherInstance(t *testing.T) {
+	s, dir := newTestManager(t)
+	// A second game instance on the same data directory.
+	other, err := NewSaveManagerWithDir(dir, WithFileLocks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LockSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LockSave("farm"); err != nil {
+		t.Errorf("LockSave again by its holder: %v", err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+
+	if err := other.LockSave("farm"); !errors.Is(err, ErrSaveLocked) {
+		t.Errorf("second LockSave = %v, want ErrSaveLocked", err)
+	}
+	if err := other.SaveGame("farm", `{"day":2}`); !errors.Is(err, ErrSaveLocked) {
+		t.Errorf("SaveGame by the other instance = %v, want ErrSaveLocked", err)
+	}
+
+	if err := s.UnlockSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, other, "farm", `{"day":2}`)
+	if err := other.LockSave("farm"); err != nil {
+		t.Errorf("LockSave after unlock: %v", err)
+	}
+	other.UnlockSave("farm")
+	if names, err := s.GetAllSaves(); err != nil || len(names) != 1 {
+		t.Errorf("GetAllSaves = %v, %v, want the lock file left out", names, err)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memFS is an in-memory FileSystem for tests, with hooks for making writes
+// and renames fail.
+type memFS struct {
+	mu         sync.Mutex
+	files      map[string][]byte
+	mtimes     map[string]time.Time
+	dirs       map[string]bool
+	syncs      int
+	writeErr   error
+	renameHook func(o, n string) error
+}
+
+func newMemFS() *memFS {
+	return &memFS{files: map[string][]byte{}, mtimes: map[string]time.Time{}, dirs: map[string]bool{}}
+}
+
+type memInfo struct {
+	name  string
+	size  int64
+	dir   bool
+	mtime time.Time
+}
+
+func (i memInfo) Name() string { return i.name }
+func (i memInfo) Size() int64  { return i.size }
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (i memInfo) ModTime() time.Time { return i.mtime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+type memFile struct {
+	fs   *memFS
+	name string
+	r    *bytes.Reader
+	w    *bytes.Buffer
+}
+
+func (f *memFile) Read(p []byte) (int, error) { return f.r.Read(p) }
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.fs.writeErr != nil {
+		return 0, f.fs.writeErr
+	}
+	return f.w.Write(p)
+}
+
+func (f *memFile) Close() error {
+	if f.w != nil {
+		f.fs.mu.Lock()
+		f.fs.files[f.name] = append([]byte(nil), f.w.Bytes()...)
+		f.fs.mtimes[f.name] = time.Now()
+		f.fs.mu.Unlock()
+	}
+	return nil
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) { return f.fs.Stat(f.name) }
+func (f *memFile) Sync() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	f.fs.syncs++
+	return nil
+}
+
+func (m *memFS) Open(name string) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dirs[name] {
+		return &memFile{fs: m, name: name, r: bytes.NewReader(nil)}, nil
+	}
+	d, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{fs: m, name: name, r: bytes.NewReader(d)}, nil
+}
+
+func (m *memFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if flag&os.O_CREATE == 0 {
+		return m.Open(name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.dirs[filepath.Dir(name)] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if flag&os.O_EXCL != 0 {
+		if _, ok := m.files[name]; ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+		}
+	}
+	buf := &bytes.Buffer{}
+	if flag&os.O_APPEND != 0 {
+		buf.Write(m.files[name])
+	}
+	m.files[name] = buf.Bytes()
+	return &memFile{fs: m, name: name, w: buf}, nil
+}
+
+func (m *memFS) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.dirs[name] {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	var out []os.DirEntry
+	for f, d := range m.files {
+		if filepath.Dir(f) == name {
+			out = append(out, fs.FileInfoToDirEntry(memInfo{filepath.Base(f), int64(len(d)), false, m.mtimes[f]}))
+		}
+	}
+	for d := range m.dirs {
+		if d != name && filepath.Dir(d) == name {
+			out = append(out, fs.FileInfoToDirEntry(memInfo{filepath.Base(d), 0, true, time.Time{}}))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
+
+func (m *memFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dirs[name] {
+		return memInfo{filepath.Base(name), 0, true, time.Time{}}, nil
+	}
+	d, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return memInfo{filepath.Base(name), int64(len(d)), false, m.mtimes[name]}, nil
+}
+
+func (m *memFS) Rename(o, n string) error {
+	if m.renameHook != nil {
+		if err := m.renameHook(o, n); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.files[o]; ok {
+		m.files[n] = d
+		m.mtimes[n] = m.mtimes[o]
+		delete(m.files, o)
+		return nil
+	}
+	if m.dirs[o] {
+		for f, d := range m.files {
+			if strings.HasPrefix(f, o+"/") {
+				m.files[n+f[len(o):]] = d
+				delete(m.files, f)
+			}
+		}
+		for d := range m.dirs {
+			if d == o || strings.HasPrefix(d, o+"/") {
+				m.dirs[n+d[len(o):]] = true
+				delete(m.dirs, d)
+			}
+		}
+		return nil
+	}
+	return &fs.PathError{Op: "rename", Path: o, Err: fs.ErrNotExist}
+}
+
+func (m *memFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; ok {
+		delete(m.files, name)
+		return nil
+	}
+	if m.dirs[name] {
+		delete(m.dirs, name)
+		return nil
+	}
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *memFS) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for f := range m.files {
+		if f == path || strings.HasPrefix(f, path+"/") {
+			delete(m.files, f)
+		}
+	}
+	for d := range m.dirs {
+		if d == path || strings.HasPrefix(d, path+"/") {
+			delete(m.dirs, d)
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Chtimes(name string, a, mt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fs.ErrNotExist}
+	}
+	m.mtimes[name] = mt
+	return nil
+}
+
+func (m *memFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for p := path; p != "/" && p != "."; p = filepath.Dir(p) {
+		m.dirs[p] = true
+	}
+	return nil
+}
+
+// badDirFS is a memFS whose ReadDir fails with dirErr, if set, and whose
+// entry for badName cannot be stat'ed.
+type badDirFS struct {
+	*memFS
+	dirErr  error
+	badName string
+}
+
+type badEntry struct{ os.DirEntry }
+
+func (badEntry) Info() (os.FileInfo, error) { return nil, os.ErrPermission }
+
+func (b *badDirFS) ReadDir(name string) ([]os.DirEntry, error) {
+	if b.dirErr != nil {
+		return nil, b.dirErr
+	}
+	entries, err := b.memFS.ReadDir(name)
+	for i, e := range entries {
+		if e.Name() == b.badName {
+			entries[i] = badEntry{e}
+		}
+	}
+	return entries, err
+}
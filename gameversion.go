@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WithGameVersion sets the version of the running game, such as "1.4.2",
+// which is recorded with every save it writes.
+func WithGameVersion(version string) Option {
+	return func(s *SaveManager) {
+		s.gameVersion = version
+	}
+}
+
+// GetSaveGameVersion returns the version of the game that last wrote
+// saveName, or "" if it was written without WithGameVersion.
+func (s *SaveManager) GetSaveGameVersion(saveName string) (string, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return "", err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	if _, err := s.savePath(saveName); err != nil {
+		return "", fmt.Errorf("get game version of save %q: %w", saveName, err)
+	}
+	header, err := s.readHeader(saveName)
+	if err != nil {
+		return "", err
+	}
+	return header.GameVersion, nil
+}
+
+// OnNewerSave registers hook to be called when a save loaded through
+// LoadGame and its variants was written by a newer version of the game than
+// the one set with WithGameVersion, which may not read it correctly.
+func (s *SaveManager) OnNewerSave(hook func(saveName, gameVersion string)) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.newerHooks = append(s.newerHooks, hook)
+}
+
+// checkGameVersion calls the OnNewerSave hooks if saveName was written by a
+// newer game version.
+func (s *SaveManager) checkGameVersion(saveName string) {
+	s.hooksMu.RLock()
+	hooks := s.newerHooks
+	s.hooksMu.RUnlock()
+	if len(hooks) == 0 || s.gameVersion == "" {
+		return
+	}
+	// The header is replaced atomically, so it can be read without the
+	// save's lock, which some callers already hold.
+	header, err := s.readHeader(saveName)
+	if err != nil || compareGameVersions(header.GameVersion, s.gameVersion) <= 0 {
+		return
+	}
+	for _, hook := range hooks {
+		s.runHook(func() { hook(saveName, header.GameVersion) })
+	}
+}
+
+// stampGameVersion records the running game version in the header of
+// saveName after it has been changed without a full write. The caller must
+// hold the save's write lock.
+func (s *SaveManager) stampGameVersion(saveName string) error {
+	header, err := s.readHeader(saveName)
+	if err != nil || header.GameVersion == s.gameVersion {
+		return err
+	}
+	header.GameVersion = s.gameVersion
+	return s.writeHeader(saveName, header)
+}
+
+// compareGameVersions compares two dotted versions such as "1.10.0" and
+// "v1.9", part by part, numerically where both parts are numbers. It returns
+// -1, 0 or 1 like strings.Compare. An empty version is older than any other.
+func compareGameVersions(a, b string) int {
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		ap, bp := "0", "0"
+		if i < len(as) {
+			ap = as[i]
+		}
+		if i < len(bs) {
+			bp = bs[i]
+		}
+		an, aerr := strconv.Atoi(ap)
+		bn, berr := strconv.Atoi(bp)
+		switch {
+		case aerr == nil && berr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case aerr != nil || berr != nil:
+			if c := strings.Compare(ap, bp); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
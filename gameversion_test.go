@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetSaveGameVersion(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSaveManagerWithDir(dir, WithGameVersion("1.10.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+	version, err := s.GetSaveGameVersion("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "1.10.0" {
+		t.Errorf("GetSaveGameVersion = %q, want 1.10.0", version)
+	}
+}
+
+func TestOnNewerSaveWarns(t *testing.T) {
+	dir := t.TempDir()
+	newer, err := NewSaveManagerWithDir(dir, WithGameVersion("1.10.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, newer, "farm", `{"day":1}`)
+
+	older, err := NewSaveManagerWithDir(dir, WithGameVersion("1.9.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warned []string
+	older.OnNewerSave(func(saveName, version string) {
+		warned = append(warned, saveName+"@"+version)
+	})
+	mustLoad(t, older, "farm")
+	if !slices.Equal(warned, []string{"farm@1.10.0"}) {
+		t.Errorf("OnNewerSave saw %v", warned)
+	}
+	// Saving again stamps the running version.
+	mustSave(t, older, "farm", `{"day":2}`)
+	mustLoad(t, older, "farm")
+	if len(warned) != 1 {
+		t.Errorf("OnNewerSave ran again for a save by the same version: %v", warned)
+	}
+}
+
+func TestCompareGameVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.2", "1.2.0", 0},
+		{"1.9.3", "1.10.0", -1},
+		{"v2", "1.99", 1},
+		{"", "1", -1},
+	} {
+		if got := compareGameVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareGameVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
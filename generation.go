@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readyPath is the marker holding a save's generation, rewritten once each
+// write of the save is fully committed, for external tools such as sync
+// clients to watch instead of the save file itself.
+func (s *SaveManager) readyPath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".ready")
+}
+
+// GetSaveGeneration returns how many times saveName has been written since
+// it was created, which its ".ready" marker file also holds. Saves written
+// by versions from before the marker report 0 until they are next saved.
+func (s *SaveManager) GetSaveGeneration(saveName string) (uint64, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return 0, err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	if _, err := s.savePath(saveName); err != nil {
+		return 0, fmt.Errorf("get generation of save %q: %w", saveName, err)
+	}
+	gen, err := s.readGeneration(saveName)
+	if err != nil {
+		return 0, fmt.Errorf("get generation of save %q: %w", saveName, err)
+	}
+	return gen, nil
+}
+
+func (s *SaveManager) readGeneration(saveName string) (uint64, error) {
+	data, err := readFile(s.fs, s.readyPath(saveName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	gen, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		// Start over rather than fail every save over a damaged marker.
+		return 0, nil
+	}
+	return gen, nil
+}
+
+// bumpGeneration atomically rewrites the ready marker of saveName with the
+// next generation. The caller must hold the save's write lock and call it
+// only once the write is committed.
+func (s *SaveManager) bumpGeneration(saveName string) error {
+	gen, err := s.readGeneration(saveName)
+	if err != nil {
+		return err
+	}
+	return s.writeFileAtomic(s.readyPath(saveName), s.fileMode, func(w io.Writer) error {
+		_, err := io.WriteString(w, strconv.FormatUint(gen+1, 10))
+		return err
+	})
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestGetSaveGenerationIncrements(t *testing.T) {
+	s, _ := newTestManager(t)
+	for i, data := range []string{`{"day":1}`, `{"day":2}`, `{"day":3}`} {
+		mustSave(t, s, "farm", data)
+		if got, err := s.GetSaveGeneration("farm"); err != nil || got != uint64(i+1) {
+			t.Errorf("GetSaveGeneration after save %d = %d, %v, want %d", i+1, got, err, i+1)
+		}
+	}
+	if err := s.SaveDelta("farm", `{"day":3}`, `{"day":4}`); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetSaveGeneration("farm"); err != nil || got != 4 {
+		t.Errorf("GetSaveGeneration after SaveDelta = %d, %v, want 4", got, err)
+	}
+}
+
+func TestDeleteSaveRemovesReadyMarker(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.DeleteSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.readyPath("farm")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ready marker left after DeleteSave: %v", err)
+	}
+	if _, err := s.GetSaveGeneration("farm"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("GetSaveGeneration of a deleted save = %v, want ErrSaveNotFound", err)
+	}
+}
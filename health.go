@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SaveHealth is what HealthCheck found for one save.
+type SaveHealth struct {
+	Name string `json:"name"`
+	// Valid reports whether the save, with its deltas applied, parses.
+	Valid bool `json:"valid"`
+	// ChecksumOK reports whether the save matches its recorded checksum.
+	// Saves written without one pass.
+	ChecksumOK bool     `json:"checksumOK"`
+	SizeBytes  int64    `json:"sizeBytes"`
+	Issues     []string `json:"issues"`
+}
+
+// HealthCheck inspects every save, reporting whether each parses, matches
+// its checksum and has readable sidecars, for players to paste into bug
+// reports. It never modifies anything. Saves are listed by name.
+func (s *SaveManager) HealthCheck() ([]SaveHealth, error) {
+	names, err := s.saveNames()
+	if err != nil {
+		return nil, fmt.Errorf("health check: %w", err)
+	}
+	slices.Sort(names)
+	report := []SaveHealth{}
+	for _, name := range names {
+		health, ok := s.checkHealth(name)
+		if ok {
+			report = append(report, health)
+		}
+	}
+	return report, nil
+}
+
+// checkHealth inspects saveName, reporting false if it was deleted in the
+// meantime.
+func (s *SaveManager) checkHealth(saveName string) (SaveHealth, bool) {
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	health := SaveHealth{Name: saveName, Issues: []string{}}
+	issue := func(format string, args ...any) {
+		health.Issues = append(health.Issues, fmt.Sprintf(format, args...))
+	}
+	filename, err := s.savePath(saveName)
+	if errors.Is(err, os.ErrNotExist) {
+		return health, false
+	}
+	if err != nil {
+		issue("%v", err)
+		return health, true
+	}
+	data, err := readFile(s.fs, filename)
+	if err != nil {
+		issue("%v", err)
+		return health, true
+	}
+	health.SizeBytes = int64(len(data))
+	if err := s.verifyChecksum(saveName, data); err != nil {
+		issue("%v", err)
+	} else {
+		health.ChecksumOK = true
+	}
+
+	ctx := context.WithValue(context.Background(), skipChecksumKey{}, true)
+	saveData, _, deltas, err := s.readSaveAndDeltas(ctx, saveName)
+	if err == nil {
+		saveData, err = s.applyDeltas(saveData, deltas)
+	}
+	switch {
+	case err != nil:
+		issue("%v", err)
+	case !json.Valid([]byte(saveData)):
+		issue("%v", ErrInvalidSaveData)
+	default:
+		health.Valid = true
+	}
+
+	if header, err := s.readHeader(saveName); err != nil {
+		issue("%v", err)
+	} else if header.Version > s.currentVersion() {
+		issue("written with schema version %d, newer than %d", header.Version, s.currentVersion())
+	}
+	var variants []string
+	for _, ext := range s.fileExts {
+		if _, err := s.fs.Stat(filepath.Join(s.dir(), saveName+ext)); err == nil {
+			variants = append(variants, saveName+ext)
+		}
+	}
+	if len(variants) > 1 {
+		issue("stored more than once: %s", strings.Join(variants, ", "))
+	}
+	if _, err := s.readTags(saveName); err != nil {
+		issue("unreadable tags: %v", err)
+	}
+	if _, err := s.readHistory(saveName); err != nil {
+		issue("unreadable history: %v", err)
+	}
+	if meta, err := readFile(s.fs, s.metaPath(saveName)); err == nil && !json.Valid(meta) {
+		issue("unreadable metadata: %v", ErrInvalidSaveData)
+	}
+	return health, true
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestHealthCheckFlagsCorruptSave(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "good", `{"day":1}`)
+	mustSave(t, s, "bad", `{"day":1}`)
+	// Truncate the save behind its checksum and break a sidecar.
+	if err := os.WriteFile(filepath.Join(dir, "bad"+defaultSaveExt), []byte(`{"day":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.tags"), []byte(`not json`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := dirContents(t, dir)
+
+	report, err := s.HealthCheck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("HealthCheck = %+v, want two saves", report)
+	}
+	bad, good := report[0], report[1]
+	if !good.Valid || !good.ChecksumOK || len(good.Issues) != 0 || good.SizeBytes != int64(len(`{"day":1}`)) {
+		t.Errorf("healthy save reported as %+v", good)
+	}
+	if bad.Valid || bad.ChecksumOK {
+		t.Errorf("corrupt save reported as %+v", bad)
+	}
+	if len(bad.Issues) != 3 {
+		t.Errorf("corrupt save issues = %q, want parse, checksum and tags", bad.Issues)
+	}
+
+	if after := dirContents(t, dir); !maps.Equal(before, after) {
+		t.Errorf("HealthCheck changed the data directory: %v, was %v", slices.Sorted(maps.Keys(after)), slices.Sorted(maps.Keys(before)))
+	}
+}
+
+// dirContents returns the contents of every file under dir, by relative path.
+func dirContents(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	contents := make(map[string]string)
+	for _, rel := range dirFiles(t, dir) {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[rel] = string(data)
+	}
+	return contents
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultHistoryLimit is how many entries each save's history keeps.
+const defaultHistoryLimit = 100
+
+// HistoryEntry records one write of a save.
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+	Note string    `json:"note,omitempty"`
+}
+
+// WithHistoryLimit sets how many entries are kept in each save's history,
+// dropping the oldest first. A limit of 0 or less turns the history off.
+func WithHistoryLimit(n int) Option {
+	return func(s *SaveManager) {
+		s.historyLimit = n
+	}
+}
+
+func (s *SaveManager) historyPath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".history")
+}
+
+type historyNoteKey struct{}
+
+// historyNote returns the note SaveGameWithNote attached to ctx.
+func historyNote(ctx context.Context) string {
+	note, _ := ctx.Value(historyNoteKey{}).(string)
+	return note
+}
+
+// SaveGameWithNote saves saveData like SaveGame and records note with the
+// write in the save's history.
+func (s *SaveManager) SaveGameWithNote(saveName, saveData, note string) error {
+	ctx := context.WithValue(context.Background(), historyNoteKey{}, note)
+	return s.SaveGameContext(ctx, saveName, saveData)
+}
+
+// GetSaveHistory returns the recorded writes of saveName, oldest first.
+func (s *SaveManager) GetSaveHistory(saveName string) ([]HistoryEntry, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return nil, err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	if _, err := s.savePath(saveName); err != nil {
+		return nil, fmt.Errorf("get history of save %q: %w", saveName, err)
+	}
+	history, err := s.readHistory(saveName)
+	if err != nil {
+		return nil, fmt.Errorf("get history of save %q: %w", saveName, err)
+	}
+	return history, nil
+}
+
+// readHistory parses the history file of saveName, one JSON entry per line.
+func (s *SaveManager) readHistory(saveName string) ([]HistoryEntry, error) {
+	history := []HistoryEntry{}
+	data, err := readFile(s.fs, s.historyPath(saveName))
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	return history, scanner.Err()
+}
+
+// recordHistory adds a write of size bytes to the history of saveName,
+// dropping the oldest entries beyond the limit. The save itself has already
+// been written, so failures only go to the error handler. The caller must hold the
+// save's lock.
+func (s *SaveManager) recordHistory(saveName string, size int64, note string) {
+	if s.historyLimit <= 0 {
+		return
+	}
+	history, err := s.readHistory(saveName)
+	if err != nil {
+		// Start over rather than keep failing on a damaged file.
+		history = nil
+	}
+	history = append(history, HistoryEntry{Time: s.now(), Size: size, Note: note})
+	if len(history) > s.historyLimit {
+		history = history[len(history)-s.historyLimit:]
+	}
+	err = s.writeFileAtomic(s.historyPath(saveName), s.fileMode, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		for _, entry := range history {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.reportError(fmt.Errorf("save history %q: %w", saveName, err))
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetSaveHistory(t *testing.T) {
+	s, dir := newTestManager(t, WithHistoryLimit(3))
+	mustSave(t, s, "farm", `1`)
+	if err := s.SaveGameWithNote("farm", `22`, "beat the boss"); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `333`)
+	history, err := s.GetSaveHistory("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("GetSaveHistory returned %d entries, want 3", len(history))
+	}
+	for i, entry := range history {
+		if entry.Size != int64(i+1) {
+			t.Errorf("entry %d has size %d, want %d", i, entry.Size, i+1)
+		}
+		if i > 0 && entry.Time.Before(history[i-1].Time) {
+			t.Errorf("entry %d is older than the one before it", i)
+		}
+	}
+	if history[1].Note != "beat the boss" {
+		t.Errorf("entry 1 has note %q", history[1].Note)
+	}
+
+	// The oldest entry is dropped past the limit.
+	mustSave(t, s, "farm", `4444`)
+	history, err = s.GetSaveHistory("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[0].Size != 2 || history[2].Size != 4 {
+		t.Errorf("GetSaveHistory past the limit = %+v", history)
+	}
+
+	if err := s.DeleteSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "farm.history")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("history not deleted with its save: %v", err)
+	}
+	if _, err := s.GetSaveHistory("farm"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("GetSaveHistory of a deleted save = %v, want ErrSaveNotFound", err)
+	}
+}
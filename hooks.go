@@ -0,0 +1,67 @@
+package main
+
+import "fmt"
+
+// WithErrorHandler sets the function given the errors the manager has no
+// caller to return to, such as those of autosaves, the optimizer, watchers,
+// history records and panicking hooks. They are discarded by default.
+func WithErrorHandler(handler func(err error)) Option {
+	return func(s *SaveManager) {
+		s.errorHandler = handler
+	}
+}
+
+// reportError passes err to the handler set with WithErrorHandler, if any.
+func (s *SaveManager) reportError(err error) {
+	if s.errorHandler != nil {
+		s.errorHandler(err)
+	}
+}
+
+// OnSave registers hook to be called with the name and size in bytes of
+// every save written through SaveGame and its variants, once the write has
+// completed. Hooks run in the order they were registered.
+func (s *SaveManager) OnSave(hook func(saveName string, size int64)) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.saveHooks = append(s.saveHooks, hook)
+}
+
+// OnLoad registers hook to be called with the name of every save loaded
+// through LoadGame and its variants. Hooks run in the order they were
+// registered.
+func (s *SaveManager) OnLoad(hook func(saveName string)) {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+	s.loadHooks = append(s.loadHooks, hook)
+}
+
+func (s *SaveManager) notifySave(saveName string, size int64) {
+	s.hooksMu.RLock()
+	hooks := s.saveHooks
+	s.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		s.runHook(func() { hook(saveName, size) })
+	}
+}
+
+func (s *SaveManager) notifyLoad(saveName string) {
+	s.hooksMu.RLock()
+	hooks := s.loadHooks
+	s.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		s.runHook(func() { hook(saveName) })
+	}
+	s.checkGameVersion(saveName)
+}
+
+// runHook calls hook, reporting rather than propagating a panic so that one
+// broken hook cannot fail a save that already succeeded or skip the others.
+func (s *SaveManager) runHook(hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.reportError(fmt.Errorf("save hook panicked: %v", r))
+		}
+	}()
+	hook()
+}
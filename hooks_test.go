@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHooksFireInOrder(t *testing.T) {
+	s, _ := newTestManager(t)
+	var got []string
+	s.OnSave(func(saveName string, size int64) {
+		got = append(got, fmt.Sprintf("save1 %s %d", saveName, size))
+	})
+	s.OnSave(func(saveName string, size int64) {
+		got = append(got, fmt.Sprintf("save2 %s %d", saveName, size))
+	})
+	s.OnLoad(func(saveName string) {
+		got = append(got, "load "+saveName)
+	})
+
+	mustSave(t, s, "farm", `{"day": 1}`)
+	mustLoad(t, s, "farm")
+	s.LoadGame("missing")
+	want := []string{"save1 farm 9", "save2 farm 9", "load farm"}
+	if !slices.Equal(got, want) {
+		t.Errorf("hooks ran as %q, want %q", got, want)
+	}
+}
+
+func TestPanickingHookDoesNotBreakSaving(t *testing.T) {
+	var errs []error
+	s, _ := newTestManager(t, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	var saved []string
+	s.OnSave(func(saveName string, size int64) { panic("achievement tracker crashed") })
+	s.OnSave(func(saveName string, size int64) { saved = append(saved, saveName) })
+	s.OnLoad(func(saveName string) { panic("analytics crashed") })
+
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2}`)
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+	if !slices.Equal(saved, []string{"farm", "farm"}) {
+		t.Errorf("hook after the panicking one saw %v", saved)
+	}
+	if len(errs) != 3 {
+		t.Errorf("error handler got %v, want the three panics", errs)
+	}
+}
+
+func TestErrorHandlerGetsAutosaveFailures(t *testing.T) {
+	errs := make(chan error, 1)
+	s, _ := newTestManager(t, WithErrorHandler(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+	mustSave(t, s, "farm"+autosaveSuffix, `{}`)
+	if err := s.SetReadOnly("farm"+autosaveSuffix, true); err != nil {
+		t.Fatal(err)
+	}
+	stop := s.StartAutosave("farm", time.Millisecond, func() string { return `{}` })
+	defer stop()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrSaveReadOnly) {
+			t.Errorf("error handler got %v, want ErrSaveReadOnly", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed autosave not reported")
+	}
+}
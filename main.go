@@ -13,11 +13,17 @@ var assets embed.FS
 
 func main() {
 	// Create an instance of the app structure
-	saveManager := NewSaveManager()
 	app := NewApp()
+	// OS file locks stop a second copy of the game from writing a save at
+	// the same time as this one.
+	saveManager, err := NewSaveManager(WithFileLocks(true), WithErrorHandler(app.logError))
+	if err != nil {
+		println("Error:", err.Error())
+		return
+	}
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "valley-legend",
 		Width:  1024,
 		Height: 768,
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func (s *SaveManager) metaPath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".meta")
+}
+
+// SaveGameWithMeta saves saveData like SaveGame and stores meta next to it,
+// so list screens can show details such as level or day count without
+// loading the whole save.
+func (s *SaveManager) SaveGameWithMeta(saveName, saveData string, meta map[string]string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	saveData, err := s.formatSaveData(saveData)
+	if err != nil {
+		return fmt.Errorf("save %q: %w", saveName, err)
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := s.saveWithSidecar(saveName, saveData, ".meta", data); err != nil {
+		return err
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return nil
+}
+
+// GetSaveMeta returns the metadata stored with saveName, which is empty if
+// the save was written without any.
+func (s *SaveManager) GetSaveMeta(saveName string) (map[string]string, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return nil, err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	if err := s.checkSaveExists(saveName); err != nil {
+		return nil, fmt.Errorf("get meta of save %q: %w", saveName, err)
+	}
+	meta := make(map[string]string)
+	data, err := s.loadSidecar(saveName, ".meta")
+	if errors.Is(err, os.ErrNotExist) {
+		return meta, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("get meta of save %q: %w", saveName, err)
+	}
+	return meta, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MigrateDataDir copies every save, sidecar and backup to newDir, checks each
+// copy against its original and then switches the manager over to newDir.
+// Files already copied intact by an earlier, interrupted run are skipped, so
+// a failed migration can simply be retried. If newDir holds a different file
+// under the same name as one of ours, nothing is copied and the migration
+// fails with ErrSaveExists. If removeOld is set the old files are removed
+// once everything has been copied. With profiles, every profile moves and
+// the manager stays in the one in use. The manager may be used meanwhile,
+// but saves written before it switches over stay in the old directory.
+func (s *SaveManager) MigrateDataDir(newDir string, removeOld bool) error {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	oldDir := s.root()
+	rel, err := filepath.Rel(oldDir, newDir)
+	if err == nil && rel == "." {
+		return nil
+	}
+	if err == nil && filepath.IsLocal(rel) {
+		return fmt.Errorf("migrate data directory to %s: inside %s", newDir, oldDir)
+	}
+	if err := s.fs.MkdirAll(newDir, s.dirMode); err != nil {
+		return fmt.Errorf("migrate data directory to %s: %w", newDir, err)
+	}
+	files, err := s.dataFiles(oldDir, "")
+	if err != nil {
+		return fmt.Errorf("migrate data directory to %s: %w", newDir, err)
+	}
+	if clash, err := s.fileClash(oldDir, newDir, files); err != nil {
+		return fmt.Errorf("migrate data directory to %s: %w", newDir, err)
+	} else if clash != "" {
+		return fmt.Errorf("migrate data directory to %s: %s: %w", newDir, clash, ErrSaveExists)
+	}
+	for _, rel := range files {
+		if err := s.migrateFile(filepath.Join(oldDir, rel), filepath.Join(newDir, rel)); err != nil {
+			return fmt.Errorf("migrate data directory to %s: %s: %w", newDir, rel, err)
+		}
+	}
+	s.dirMu.Lock()
+	s.rootDir = newDir
+	s.dataDir = newDir
+	if s.profile != "" {
+		s.dataDir = filepath.Join(newDir, profilesDir, s.profile)
+	}
+	s.dirMu.Unlock()
+	if !removeOld {
+		return nil
+	}
+	for _, rel := range files {
+		if err := s.fs.Remove(filepath.Join(oldDir, rel)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove old data directory %s: %w", oldDir, err)
+		}
+	}
+	// Only empty directories can be removed, leaving anything that isn't ours.
+	profiles, _ := s.fs.ReadDir(filepath.Join(oldDir, profilesDir))
+	for _, profile := range profiles {
+		if profile.IsDir() {
+			dir := filepath.Join(oldDir, profilesDir, profile.Name())
+			s.removeDataSubdirs(dir)
+			s.fs.Remove(dir)
+		}
+	}
+	s.fs.Remove(filepath.Join(oldDir, profilesDir))
+	s.removeDataSubdirs(oldDir)
+	return nil
+}
+
+// removeDataSubdirs removes the backup, bundle and delta directories under
+// dir that are empty.
+func (s *SaveManager) removeDataSubdirs(dir string) {
+	for _, sub := range []string{"backups", "bundles", "deltas"} {
+		subdirs, _ := s.fs.ReadDir(filepath.Join(dir, sub))
+		for _, subdir := range subdirs {
+			s.fs.Remove(filepath.Join(dir, sub, subdir.Name()))
+		}
+		s.fs.Remove(filepath.Join(dir, sub))
+	}
+}
+
+// dataFiles lists the files under dir/rel that belong to the data directory,
+// relative to dir, leaving out temporary and lock files.
+func (s *SaveManager) dataFiles(dir, rel string) ([]string, error) {
+	entries, err := s.fs.ReadDir(filepath.Join(dir, rel))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := filepath.Join(rel, entry.Name())
+		if entry.IsDir() {
+			sub, err := s.dataFiles(dir, name)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, sub...)
+			continue
+		}
+		if strings.HasSuffix(name, tmpExt) || strings.HasSuffix(name, ".lock") {
+			continue
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// migrateFile copies src to dst unless dst already holds the same contents,
+// and checks the copy.
+func (s *SaveManager) migrateFile(src, dst string) error {
+	want, err := s.fileChecksum(src)
+	if err != nil {
+		return err
+	}
+	if got, err := s.fileChecksum(dst); err == nil && bytes.Equal(got, want) {
+		return nil
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(dst), s.dirMode); err != nil {
+		return err
+	}
+	if err := s.copyFileAtomic(src, dst, s.fileMode); err != nil {
+		return err
+	}
+	got, err := s.fileChecksum(dst)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("copy does not match the original: %w", ErrChecksumMismatch)
+	}
+	return nil
+}
+
+// moveDataFiles moves files, relative to srcDir, into dstDir save by save. A
+// save is left in srcDir, sidecars and all, if any of its files would
+// replace a different file in dstDir or dstDir holds the save in another
+// form, such as compressed. Identical files were moved by an interrupted
+// run, which is completed. Empty backup, bundle and delta directories left
+// in srcDir are removed.
+func (s *SaveManager) moveDataFiles(srcDir, dstDir string, files []string) error {
+	owners := make(map[string][]string)
+	var order []string
+	for _, rel := range files {
+		owner := s.profileFileOwner(rel)
+		if _, ok := owners[owner]; !ok {
+			order = append(order, owner)
+		}
+		owners[owner] = append(owners[owner], rel)
+	}
+	var moved []string
+	for _, owner := range order {
+		group := owners[owner]
+		clash, err := s.fileClash(srcDir, dstDir, group)
+		if err != nil {
+			return err
+		}
+		for _, ext := range s.fileExts {
+			rel := owner + ext
+			if clash != "" || slices.Contains(group, rel) {
+				continue
+			}
+			if _, err := s.fs.Stat(filepath.Join(dstDir, rel)); err == nil {
+				clash = rel
+			}
+		}
+		if clash != "" {
+			s.reportError(fmt.Errorf("move %q to %s: %s: %w; left in %s", owner, dstDir, clash, ErrSaveExists, srcDir))
+			continue
+		}
+		for _, rel := range group {
+			if err := s.migrateFile(filepath.Join(srcDir, rel), filepath.Join(dstDir, rel)); err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+		}
+		moved = append(moved, group...)
+	}
+	for _, rel := range moved {
+		if err := s.fs.Remove(filepath.Join(srcDir, rel)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	s.removeDataSubdirs(srcDir)
+	return nil
+}
+
+// fileClash returns the first of files, relative to srcDir, that has a
+// different file of the same name in dstDir, or "" if none does. Identical
+// files were copied by an interrupted run.
+func (s *SaveManager) fileClash(srcDir, dstDir string, files []string) (string, error) {
+	for _, rel := range files {
+		have, err := s.fileChecksum(filepath.Join(dstDir, rel))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		want, err := s.fileChecksum(filepath.Join(srcDir, rel))
+		if err != nil {
+			return "", err
+		}
+		if !bytes.Equal(have, want) {
+			return rel, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *SaveManager) fileChecksum(filename string) ([]byte, error) {
+	f, err := s.fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateDataDir(t *testing.T) {
+	s, oldDir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2}`)
+	mustSave(t, s, "mine", `{"depth":40}`)
+	if err := s.SetLastSave("mine"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveBundle("valley", map[string]string{"world": `{}`}); err != nil {
+		t.Fatal(err)
+	}
+
+	newDir := filepath.Join(t.TempDir(), "saves")
+	// Left by an earlier migration that was interrupted: one save copied
+	// and another half way.
+	if err := os.MkdirAll(newDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(newDir, "mine.json"), []byte(`{"depth":40}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(newDir, "farm.json"+tmpExt), []byte(`{"da`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MigrateDataDir(newDir, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.DataDir() != newDir {
+		t.Errorf("DataDir = %s, want %s", s.DataDir(), newDir)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame(farm) = %s", got)
+	}
+	if got := mustLoad(t, s, "mine"); got != `{"depth":40}` {
+		t.Errorf("LoadGame(mine) = %s", got)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "mine" {
+		t.Errorf("GetLastSave = %q, %v", last, err)
+	}
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 1 {
+		t.Errorf("ListBackups = %v, %v", ids, err)
+	}
+	if parts, err := s.LoadBundle("valley"); err != nil || parts["world"] != `{}` {
+		t.Errorf("LoadBundle = %v, %v", parts, err)
+	}
+	if _, err := os.Stat(filepath.Join(newDir, "farm.json")); err != nil {
+		t.Errorf("save not read from the new directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(oldDir, "farm.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("old save not removed: %v", err)
+	}
+
+	// Running it again is a no-op.
+	if err := s.MigrateDataDir(newDir, true); err != nil {
+		t.Errorf("migrating to the current directory: %v", err)
+	}
+}
+
+func TestMigrateDataDirIntoItself(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.MigrateDataDir(filepath.Join(dir, "sub"), false); err == nil {
+		t.Error("MigrateDataDir into a subdirectory of the data directory succeeded")
+	}
+}
+
+func TestMigrateDataDirNonEmptyDestination(t *testing.T) {
+	s, oldDir := newTestManager(t)
+	mustSave(t, s, "farm", `{"old":1}`)
+	mustSave(t, s, "mine", `{"depth":40}`)
+	newDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(newDir, "farm.json"), []byte(`{"precious":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.MigrateDataDir(newDir, true); !errors.Is(err, ErrSaveExists) {
+		t.Fatalf("MigrateDataDir over a different save = %v, want ErrSaveExists", err)
+	}
+	data, err := os.ReadFile(filepath.Join(newDir, "farm.json"))
+	if err != nil || string(data) != `{"precious":1}` {
+		t.Errorf("save in the new directory = %s, %v, want it untouched", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(newDir, "mine.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("save copied despite the clash: %v", err)
+	}
+	if s.DataDir() != oldDir {
+		t.Errorf("DataDir = %s, want %s", s.DataDir(), oldDir)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"old":1}` {
+		t.Errorf("LoadGame(farm) = %s", got)
+	}
+}
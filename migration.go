@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type migration struct {
+	toVersion int
+	fn        func(string) (string, error)
+}
+
+// saveHeader is stored next to each save in saveName+".header".
+type saveHeader struct {
+	Version     int    `json:"version"`
+	GameVersion string `json:"gameVersion,omitempty"`
+}
+
+// RegisterMigration registers fn to upgrade save data from fromVersion to
+// toVersion. The highest registered toVersion becomes the version stamped into
+// new saves, and LoadGame runs the chain of migrations on older ones.
+func (s *SaveManager) RegisterMigration(fromVersion, toVersion int, fn func(string) (string, error)) error {
+	if fromVersion < 0 || toVersion <= fromVersion {
+		return fmt.Errorf("invalid migration from version %d to %d", fromVersion, toVersion)
+	}
+	if fn == nil {
+		return fmt.Errorf("migration from version %d has no function", fromVersion)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.migrations == nil {
+		s.migrations = make(map[int]migration)
+	}
+	if _, ok := s.migrations[fromVersion]; ok {
+		return fmt.Errorf("migration from version %d already registered", fromVersion)
+	}
+	s.migrations[fromVersion] = migration{toVersion: toVersion, fn: fn}
+	return nil
+}
+
+func (s *SaveManager) currentVersion() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.currentVersionLocked()
+}
+
+func (s *SaveManager) currentVersionLocked() int {
+	version := 0
+	for _, m := range s.migrations {
+		if m.toVersion > version {
+			version = m.toVersion
+		}
+	}
+	return version
+}
+
+func (s *SaveManager) migrate(saveData string, from int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	target := s.currentVersionLocked()
+	for version := from; version < target; {
+		m, ok := s.migrations[version]
+		if !ok {
+			return "", fmt.Errorf("no migration registered from version %d", version)
+		}
+		migrated, err := m.fn(saveData)
+		if err != nil {
+			return "", fmt.Errorf("migrate save from version %d to %d: %w", version, m.toVersion, err)
+		}
+		saveData = migrated
+		version = m.toVersion
+	}
+	return saveData, nil
+}
+
+func (s *SaveManager) headerPath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".header")
+}
+
+// readHeader returns the header for saveName. Saves written before headers
+// existed have none and are reported as version 0.
+func (s *SaveManager) readHeader(saveName string) (saveHeader, error) {
+	var header saveHeader
+	data, err := readFile(s.fs, s.headerPath(saveName))
+	if errors.Is(err, os.ErrNotExist) {
+		return header, nil
+	}
+	if err != nil {
+		return header, err
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return header, fmt.Errorf("read header of save %q: %w", saveName, err)
+	}
+	return header, nil
+}
+
+func (s *SaveManager) writeHeader(saveName string, header saveHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	return s.writeFileAtomic(s.headerPath(saveName), s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
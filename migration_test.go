@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadGameRunsMigrationChain(t *testing.T) {
+	s, dir := newTestManager(t)
+	// A save written before versions existed, with no header.
+	if err := os.WriteFile(filepath.Join(dir, "farm.json"), []byte(`{"money":5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	// Registered out of order; the chain still runs 0→1→2.
+	err := s.RegisterMigration(1, 2, func(data string) (string, error) {
+		calls = append(calls, "1→2")
+		return strings.Replace(data, "}", `,"day":1}`, 1), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.RegisterMigration(0, 1, func(data string) (string, error) {
+		calls = append(calls, "0→1")
+		return strings.Replace(data, `"money"`, `"gold"`, 1), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"gold":5,"day":1}`
+	if got := mustLoad(t, s, "farm"); got != want {
+		t.Fatalf("LoadGame = %s, want %s", got, want)
+	}
+	if strings.Join(calls, " ") != "0→1 1→2" {
+		t.Errorf("migrations ran as %v", calls)
+	}
+	header, err := s.readHeader("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 {
+		t.Errorf("header version = %d, want 2", header.Version)
+	}
+
+	// The upgraded save was written back, so loading again migrates nothing.
+	calls = nil
+	if got := mustLoad(t, s, "farm"); got != want {
+		t.Errorf("second LoadGame = %s, want %s", got, want)
+	}
+	if len(calls) != 0 {
+		t.Errorf("migrations ran again: %v", calls)
+	}
+}
+
+func TestSaveGameStampsCurrentVersion(t *testing.T) {
+	s, _ := newTestManager(t)
+	identity := func(data string) (string, error) { return data, nil }
+	s.RegisterMigration(0, 1, identity)
+	s.RegisterMigration(1, 2, identity)
+	mustSave(t, s, "farm", `{}`)
+	header, err := s.readHeader("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 {
+		t.Errorf("header version = %d, want 2", header.Version)
+	}
+}
+
+func TestRegisterMigrationRejectsInvalid(t *testing.T) {
+	s, _ := newTestManager(t)
+	identity := func(data string) (string, error) { return data, nil }
+	for _, tc := range []struct {
+		name     string
+		from, to int
+		fn       func(string) (string, error)
+	}{
+		{"backwards", 2, 1, identity},
+		{"same version", 1, 1, identity},
+		{"negative", -1, 1, identity},
+		{"no function", 0, 1, nil},
+	} {
+		if err := s.RegisterMigration(tc.from, tc.to, tc.fn); err == nil {
+			t.Errorf("%s: RegisterMigration(%d, %d) succeeded", tc.name, tc.from, tc.to)
+		}
+	}
+	if err := s.RegisterMigration(0, 1, identity); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RegisterMigration(0, 2, identity); err == nil {
+		t.Error("second migration from version 0 was accepted")
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MessagePackCodec stores saves as MessagePack, which is smaller and faster
+// to parse than JSON. Values other than those encoding/json decodes into an
+// any are converted through JSON first.
+type MessagePackCodec struct{}
+
+func (MessagePackCodec) Extension() string { return ".msgpack" }
+
+func (MessagePackCodec) Marshal(v any) ([]byte, error) {
+	var e msgpackEncoder
+	if err := e.encode(v); err != nil {
+		if _, ok := err.(unsupportedTypeError); !ok {
+			return nil, err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		state, err := decodeState(string(data))
+		if err != nil {
+			return nil, err
+		}
+		e.buf = e.buf[:0]
+		if err := e.encode(state); err != nil {
+			return nil, err
+		}
+	}
+	return e.buf, nil
+}
+
+// Unmarshal decodes data into v. Numbers are decoded as json.Number when v
+// is an *any, and maps as map[string]any.
+func (MessagePackCodec) Unmarshal(data []byte, v any) error {
+	d := msgpackDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if len(d.data) != d.pos {
+		return msgpackErrorf("%d bytes of trailing data", len(d.data)-d.pos)
+	}
+	if p, ok := v.(*any); ok {
+		*p = value
+		return nil
+	}
+	text, err := encodeState(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(text, v)
+}
+
+type unsupportedTypeError struct{ v any }
+
+func (e unsupportedTypeError) Error() string {
+	return fmt.Sprintf("msgpack: unsupported type %T", e.v)
+}
+
+func msgpackErrorf(format string, args ...any) error {
+	return fmt.Errorf("%w: msgpack: %s", ErrInvalidSaveData, fmt.Sprintf(format, args...))
+}
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) encode(v any) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, 0xc0)
+	case bool:
+		if v {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			e.encodeInt(i)
+		} else if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			e.encodeUint(u)
+		} else if f, err := v.Float64(); err == nil {
+			e.encodeFloat(f)
+		} else {
+			return err
+		}
+	case float64:
+		e.encodeFloat(v)
+	case float32:
+		e.encodeFloat(float64(v))
+	case int:
+		e.encodeInt(int64(v))
+	case int64:
+		e.encodeInt(v)
+	case int32:
+		e.encodeInt(int64(v))
+	case uint64:
+		e.encodeUint(v)
+	case uint32:
+		e.encodeUint(uint64(v))
+	case string:
+		e.encodeString(v)
+	case []any:
+		e.encodeLength(len(v), 0x90, 0xdc, 0xdd)
+		for _, elem := range v {
+			if err := e.encode(elem); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		e.encodeLength(len(v), 0x80, 0xde, 0xdf)
+		// Sorted so that the same state always encodes the same way.
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			e.encodeString(k)
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return unsupportedTypeError{v}
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeInt(i int64) {
+	switch {
+	case i >= 0:
+		e.encodeUint(uint64(i))
+	case i >= -32:
+		e.buf = append(e.buf, byte(i))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xd2), uint32(i))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xd3), uint64(i))
+	}
+}
+
+func (e *msgpackEncoder) encodeUint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, 0xce), uint32(u))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcf), u)
+	}
+}
+
+func (e *msgpackEncoder) encodeFloat(f float64) {
+	e.buf = binary.BigEndian.AppendUint64(append(e.buf, 0xcb), math.Float64bits(f))
+}
+
+func (e *msgpackEncoder) encodeString(str string) {
+	if len(str) < 32 {
+		e.buf = append(e.buf, 0xa0|byte(len(str)))
+	} else if len(str) <= math.MaxUint8 {
+		e.buf = append(e.buf, 0xd9, byte(len(str)))
+	} else {
+		e.encodeLength(len(str), 0, 0xda, 0xdb)
+	}
+	e.buf = append(e.buf, str...)
+}
+
+// encodeLength writes the header of an array, map or long string of n
+// elements, using the fix variant if fix is not 0 and n fits in it.
+func (e *msgpackEncoder) encodeLength(n int, fix, code16, code32 byte) {
+	switch {
+	case fix != 0 && n < 16:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, code16), uint16(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, code32), uint32(n))
+	}
+}
+
+// msgpackMaxDepth bounds the nesting of decoded values, so that corrupt
+// input cannot exhaust the stack.
+const msgpackMaxDepth = 10000
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) decode(depth int) (any, error) {
+	if depth > msgpackMaxDepth {
+		return nil, msgpackErrorf("nesting too deep")
+	}
+	c, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return json.Number(strconv.Itoa(int(c))), nil
+	case c >= 0xe0:
+		return json.Number(strconv.Itoa(int(int8(c)))), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		n, err := d.uint(1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xc5, 0xda:
+		n, err := d.uint(2)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xc6, 0xdb:
+		n, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xca:
+		n, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(float64(math.Float32frombits(uint32(n))))
+	case 0xcb:
+		n, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(math.Float64frombits(n))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend from the encoded width.
+		shift := 64 - 8*size
+		return json.Number(strconv.FormatInt(int64(n<<shift)>>shift, 10)), nil
+	case 0xdc, 0xde:
+		n, err := d.uint(2)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0xdc {
+			return d.decodeArray(int(n), depth)
+		}
+		return d.decodeMap(int(n), depth)
+	case 0xdd, 0xdf:
+		n, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0xdd {
+			return d.decodeArray(int(n), depth)
+		}
+		return d.decodeMap(int(n), depth)
+	}
+	return nil, msgpackErrorf("unsupported type byte %#x", c)
+}
+
+func (d *msgpackDecoder) decodeArray(n int, depth int) (any, error) {
+	// Every element takes at least a byte, which bounds n on corrupt input.
+	if n > len(d.data)-d.pos {
+		return nil, msgpackErrorf("array of %d elements is truncated", n)
+	}
+	arr := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int, depth int) (any, error) {
+	if n > (len(d.data)-d.pos)/2 {
+		return nil, msgpackErrorf("map of %d entries is truncated", n)
+	}
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, msgpackErrorf("map key %v is not a string", k)
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func (d *msgpackDecoder) decodeString(n int) (any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, msgpackErrorf("string of %d bytes is truncated", n)
+	}
+	str := string(d.data[d.pos : d.pos+n])
+	d.pos += n
+	return str, nil
+}
+
+func (d *msgpackDecoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, msgpackErrorf("unexpected end of data")
+	}
+	c := d.data[d.pos]
+	d.pos++
+	return c, nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	if size > len(d.data)-d.pos {
+		return 0, msgpackErrorf("unexpected end of data")
+	}
+	var n uint64
+	for _, b := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(b)
+	}
+	d.pos += size
+	return n, nil
+}
+
+// floatNumber returns f as a json.Number, which JSON cannot hold if it is not
+// finite.
+func floatNumber(f float64) (any, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, msgpackErrorf("%v cannot be represented in JSON", f)
+	}
+	str := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".eE") {
+		// Keep it a float, as it was encoded.
+		str += ".0"
+	}
+	return json.Number(str), nil
+}
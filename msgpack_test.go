@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMessagePackRoundTrip(t *testing.T) {
+	for _, saveData := range []string{
+		`{}`,
+		`[]`,
+		`null`,
+		`{"a":1,"b":[1.5,true,false,null,"x"],"c":{"d":-70000}}`,
+		`{"big":18446744073709551615,"f":2.0,"neg":-9223372036854775808}`,
+		`{"s":"` + strings.Repeat("z", 70000) + `","unicode":"存档"}`,
+		`[` + strings.Repeat(`0,`, 70000) + `0]`,
+	} {
+		state, err := decodeState(saveData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := (MessagePackCodec{}).Marshal(state)
+		if err != nil {
+			t.Errorf("Marshal(%.40s): %v", saveData, err)
+			continue
+		}
+		var got any
+		if err := (MessagePackCodec{}).Unmarshal(data, &got); err != nil {
+			t.Errorf("Unmarshal(%.40s): %v", saveData, err)
+			continue
+		}
+		text, err := encodeState(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(text) != saveData {
+			t.Errorf("round trip of %.40s gave %.40s", saveData, text)
+		}
+	}
+}
+
+func TestMessagePackEncoding(t *testing.T) {
+	state, err := decodeState(`{"a":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := (MessagePackCodec{}).Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A fixmap of one entry, a fixstr key and a positive fixint.
+	if want := []byte{0x81, 0xa1, 'a', 0x01}; !bytes.Equal(data, want) {
+		t.Errorf("Marshal = % x, want % x", data, want)
+	}
+}
+
+func TestMessagePackStruct(t *testing.T) {
+	type player struct {
+		Name string
+		Day  int
+	}
+	data, err := (MessagePackCodec{}).Marshal(player{"Ann", 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got player
+	if err := (MessagePackCodec{}).Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != (player{"Ann", 7}) {
+		t.Errorf("Unmarshal = %+v", got)
+	}
+}
+
+func TestMessagePackCorruptInput(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated string", []byte{0xa5, 'a', 'b'}},
+		{"truncated map", []byte{0x81, 0xa1}},
+		{"huge array header", []byte{0xdd, 0xff, 0xff, 0xff, 0xff}},
+		{"huge map header", []byte{0xdf, 0xff, 0xff, 0xff, 0xff}},
+		{"truncated int", []byte{0xcd, 0x01}},
+		{"non-string key", []byte{0x81, 0x01, 0x01}},
+		{"reserved byte", []byte{0xc1}},
+		{"trailing data", []byte{0xc0, 0xc0}},
+		{"nesting too deep", append(bytes.Repeat([]byte{0x91}, msgpackMaxDepth+2), 0xc0)},
+	} {
+		var v any
+		if err := (MessagePackCodec{}).Unmarshal(tc.data, &v); !errors.Is(err, ErrInvalidSaveData) {
+			t.Errorf("%s: Unmarshal = %v, want ErrInvalidSaveData", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StartOptimizer compresses, in the background, the saves stored
+// uncompressed that have not been modified within idleThreshold, so that
+// saving never waits on compression. It makes a pass straight away and then
+// one every idleThreshold until the returned stop function is called. Saves
+// that are being read or written, here or by another process holding the OS
+// lock, are skipped until a later pass, and a compressed save keeps its
+// modification time. Failures go to the handler set with WithErrorHandler.
+// stop waits for the save being compressed, if any, and is safe to call more
+// than once.
+func (s *SaveManager) StartOptimizer(idleThreshold time.Duration) (stop func()) {
+	if idleThreshold <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(idleThreshold)
+		defer ticker.Stop()
+		for {
+			s.optimizeSaves(idleThreshold, done)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// optimizeSaves makes one optimizer pass, returning early once done is
+// closed.
+func (s *SaveManager) optimizeSaves(idleThreshold time.Duration, done <-chan struct{}) {
+	names, err := s.saveNames()
+	if err != nil {
+		s.reportError(fmt.Errorf("optimize saves: %w", err))
+		return
+	}
+	for _, saveName := range names {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if err := s.optimizeSave(saveName, s.now().Add(-idleThreshold)); err != nil {
+			s.reportError(fmt.Errorf("optimize save %q: %w", saveName, err))
+		}
+	}
+}
+
+// optimizeSave compresses saveName if it is stored uncompressed, was last
+// modified before cutoff and neither its lock nor its OS lock is held.
+func (s *SaveManager) optimizeSave(saveName string, cutoff time.Time) error {
+	mu := s.saveLock(saveName)
+	if !mu.TryLock() {
+		return nil
+	}
+	defer mu.Unlock()
+	filename, err := s.savePath(saveName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(filename, gzExt) {
+		return nil
+	}
+	fi, err := s.fs.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if fi.ModTime().After(cutoff) {
+		return nil
+	}
+	var report VacuumReport
+	err = s.compressSave(saveName, filename, &report)
+	if errors.Is(err, ErrSaveLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if report.SavesCompressed == 0 {
+		return nil
+	}
+	return s.fs.Chtimes(filename+gzExt, fi.ModTime(), fi.ModTime())
+}
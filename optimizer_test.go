@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartOptimizerCompressesIdleSaves(t *testing.T) {
+	s, dir := newTestManager(t)
+	data := `{"map":"` + strings.Repeat("ab", 500) + `"}`
+	mustSave(t, s, "old", data)
+	mustSave(t, s, "recent", data)
+	idle := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	setModTime(t, dir, "old", idle)
+
+	stop := s.StartOptimizer(time.Hour)
+	defer stop()
+	compressed := filepath.Join(dir, "old"+defaultSaveExt+gzExt)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if _, err := os.Stat(compressed); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("optimizer did not compress the idle save")
+		}
+	}
+	stop()
+	stop()
+
+	if _, err := os.Stat(filepath.Join(dir, "old"+defaultSaveExt)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("uncompressed copy of the idle save left behind: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "recent"+defaultSaveExt)); err != nil {
+		t.Errorf("recent save was touched: %v", err)
+	}
+	if got := mustLoad(t, s, "old"); got != data {
+		t.Errorf("LoadGame of compressed save = %d bytes, want %d", len(got), len(data))
+	}
+	info, err := s.StatSave("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime.Equal(idle) {
+		t.Errorf("compressed save ModTime = %v, want %v", info.ModTime, idle)
+	}
+}
+
+func TestOptimizerSkipsLockedSave(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	mu := s.saveLock("farm")
+	mu.RLock()
+	err := s.optimizeSave("farm", time.Now().Add(time.Hour))
+	mu.RUnlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "farm"+defaultSaveExt)); err != nil {
+		t.Errorf("locked save was compressed: %v", err)
+	}
+}
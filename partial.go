@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LoadGamePartial is LoadGame for saves that may be damaged. If saveName
+// loads normally it is returned with complete set. If it is truncated or
+// corrupt towards the end, as after running out of disk space, it returns
+// the longest leading part of the save that can be made valid JSON by
+// closing the objects and arrays left open, with complete unset, so that the
+// game can offer to load what survived. This is best effort: values cut off
+// part way are dropped, deltas are not applied, and encrypted saves and
+// saves in formats other than JSON cannot be recovered.
+func (s *SaveManager) LoadGamePartial(saveName string) (data string, complete bool, err error) {
+	saveData, err := s.LoadGame(saveName)
+	if err == nil {
+		return saveData, true, nil
+	}
+	if !isCorruption(err) {
+		return "", false, err
+	}
+	recovered, recErr := s.recoverSave(saveName)
+	if recErr != nil {
+		return "", false, err
+	}
+	return recovered, false, nil
+}
+
+// recoverSave returns what recoverJSON makes of whatever can be read of
+// saveName, ignoring its checksum.
+func (s *SaveManager) recoverSave(saveName string) (string, error) {
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	r, version, err := s.openSaveChecked(saveName, false)
+	if err != nil {
+		mu.RUnlock()
+		return "", err
+	}
+	// A truncated compressed save still yields everything before the cut.
+	raw, _ := io.ReadAll(r)
+	codec := r.codec
+	r.Close()
+	mu.RUnlock()
+	if !isJSONCodec(codec) {
+		return "", fmt.Errorf("recover save %q: not stored as JSON", saveName)
+	}
+	saveData, ok := recoverJSON(bytes.TrimPrefix(raw, utf8BOM))
+	if !ok {
+		return "", ErrInvalidSaveData
+	}
+	if version < s.currentVersion() {
+		return s.migrate(saveData, version)
+	}
+	return saveData, nil
+}
+
+// recoverJSON returns the longest prefix of data that ends after a complete
+// value, with the objects and arrays still open at that point closed. It
+// reports false if there is no such prefix.
+func recoverJSON(data []byte) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	// stack holds the closing delimiter of each open container, and
+	// expectKey whether that object's next token is a key.
+	var stack []byte
+	var expectKey []bool
+	cut, cutStack := -1, ""
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		end := int(dec.InputOffset())
+		if n := len(stack); n > 0 && stack[n-1] == '}' && expectKey[n-1] {
+			if _, ok := tok.(json.Delim); !ok {
+				// An object key; the value is still to come.
+				expectKey[n-1] = false
+				continue
+			}
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			closer := byte('}')
+			if tok == json.Delim('[') {
+				closer = ']'
+			}
+			stack = append(stack, closer)
+			expectKey = append(expectKey, closer == '}')
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			expectKey = expectKey[:len(expectKey)-1]
+		default:
+			if _, ok := tok.(json.Number); ok && end == len(data) {
+				// A number at the very end may have lost digits.
+				continue
+			}
+		}
+		if n := len(stack); n > 0 && stack[n-1] == '}' {
+			expectKey[n-1] = true
+		}
+		cut = end
+		cutStack = closers(stack)
+		if len(stack) == 0 {
+			break
+		}
+	}
+	if cut < 0 {
+		return "", false
+	}
+	recovered := string(data[:cut]) + cutStack
+	if !json.Valid([]byte(recovered)) {
+		return "", false
+	}
+	return recovered, true
+}
+
+// closers returns the delimiters that close stack, innermost first.
+func closers(stack []byte) string {
+	var b strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		b.WriteByte(stack[i])
+	}
+	return b.String()
+}
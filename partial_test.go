@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecoverJSON(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{`{"a":1,"b":[1,2,{"c":"x"`, `{"a":1,"b":[1,2,{"c":"x"}]}`},
+		{`{"a":1,"b":[1,2,{"c":"x`, `{"a":1,"b":[1,2,{}]}`},
+		{`{"a":1,"b":[1,2,{"c"`, `{"a":1,"b":[1,2,{}]}`},
+		{`{"a":1,"b":12`, `{"a":1}`},
+		{`{"a":1,"b":true`, `{"a":1,"b":true}`},
+		{`{"a":1,`, `{"a":1}`},
+		{`[1, 2, [3`, `[1, 2, []]`},
+		{`{"a":1}trailing`, `{"a":1}`},
+		{`{`, `{}`},
+	} {
+		if got, ok := recoverJSON([]byte(tc.in)); !ok || got != tc.want {
+			t.Errorf("recoverJSON(%q) = %q, %v, want %q", tc.in, got, ok, tc.want)
+		}
+	}
+	if got, ok := recoverJSON([]byte(`xx`)); ok {
+		t.Errorf("recoverJSON(%q) = %q, want failure", "xx", got)
+	}
+}
+
+func TestLoadGamePartialTruncated(t *testing.T) {
+	full := `{"player":{"name":"Ann"},"map":[` + strings.Repeat(`{"region":"explored"},`, 2000) + `{"region":"last"}]}`
+	for _, compress := range []bool{false, true} {
+		s, _ := newTestManager(t, WithCompression(compress))
+		mustSave(t, s, "farm", full)
+		got, complete, err := s.LoadGamePartial("farm")
+		if err != nil || !complete || got != full {
+			t.Fatalf("compress=%v: LoadGamePartial of intact save = %d bytes, %v, %v", compress, len(got), complete, err)
+		}
+
+		// Cut the file short, as a full disk would.
+		filename, err := s.savePath("farm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, raw[:len(raw)*9/10], 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, complete, err = s.LoadGamePartial("farm")
+		if err != nil {
+			t.Fatalf("compress=%v: LoadGamePartial of truncated save: %v", compress, err)
+		}
+		if complete {
+			t.Errorf("compress=%v: truncated save reported complete", compress)
+		}
+		if !json.Valid([]byte(got)) || !strings.HasPrefix(got, `{"player":{"name":"Ann"},"map":[{"region":"explored"}`) {
+			t.Errorf("compress=%v: recovered %d bytes that are not a valid prefix", compress, len(got))
+		}
+	}
+}
+
+func TestLoadGamePartialMissing(t *testing.T) {
+	s, _ := newTestManager(t)
+	if _, _, err := s.LoadGamePartial("nobody"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadGamePartial of a missing save = %v, want ErrSaveNotFound", err)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// profilesDir holds one data directory per profile, under the root data
+// directory.
+const profilesDir = "profiles"
+
+// defaultProfile is the profile the saves kept before profiles were used
+// are moved into.
+const defaultProfile = "default"
+
+// activeProfileFile, under profilesDir, records the profile in use so that
+// it is picked again after a restart.
+const activeProfileFile = ".active"
+
+var (
+	// ErrProfileNotFound is returned for a profile that does not exist.
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrProfileExists is returned by CreateProfile for a profile that
+	// already exists.
+	ErrProfileExists = errors.New("profile already exists")
+	// ErrProfileActive is returned by DeleteProfile for the profile in use.
+	ErrProfileActive = errors.New("profile is in use")
+)
+
+// SwitchProfile points the manager at the saves of the profile name, each
+// profile having its own saves, backups and last-save marker. The first
+// profile method called moves any saves kept outside profiles into the
+// "default" profile and switches to it; a save that clashes with one already
+// in the default profile is left where it is. The profile in use is
+// remembered, and a manager created later for the same data directory starts
+// in it. Autosaves and other goroutines using the manager carry on in the
+// new profile, but watchers started with WatchSaves keep watching the old
+// one.
+func (s *SaveManager) SwitchProfile(name string) error {
+	if err := validateSaveName(name); err != nil {
+		return err
+	}
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	if err := s.initProfiles(); err != nil {
+		return fmt.Errorf("switch to profile %q: %w", name, err)
+	}
+	dir := s.profileDir(name)
+	if fi, err := s.fs.Stat(dir); errors.Is(err, os.ErrNotExist) || err == nil && !fi.IsDir() {
+		return fmt.Errorf("switch to profile %q: %w", name, ErrProfileNotFound)
+	} else if err != nil {
+		return fmt.Errorf("switch to profile %q: %w", name, err)
+	}
+	if err := s.useProfile(name); err != nil {
+		return fmt.Errorf("switch to profile %q: %w", name, err)
+	}
+	return nil
+}
+
+// CurrentProfile returns the profile in use, or "" if profiles have never
+// been used in the data directory.
+func (s *SaveManager) CurrentProfile() string {
+	s.dirMu.RLock()
+	defer s.dirMu.RUnlock()
+	return s.profile
+}
+
+// ListProfiles returns the names of the profiles, sorted.
+func (s *SaveManager) ListProfiles() ([]string, error) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	if err := s.initProfiles(); err != nil {
+		return nil, fmt.Errorf("list profiles: %w", err)
+	}
+	entries, err := s.fs.ReadDir(filepath.Join(s.root(), profilesDir))
+	if err != nil {
+		return nil, fmt.Errorf("list profiles: %w", err)
+	}
+	profiles := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && validateSaveName(entry.Name()) == nil {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	slices.Sort(profiles)
+	return profiles, nil
+}
+
+// CreateProfile creates the empty profile name, returning ErrProfileExists if
+// it is taken. It does not switch to it.
+func (s *SaveManager) CreateProfile(name string) error {
+	if err := validateSaveName(name); err != nil {
+		return err
+	}
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	if err := s.initProfiles(); err != nil {
+		return fmt.Errorf("create profile %q: %w", name, err)
+	}
+	dir := s.profileDir(name)
+	if _, err := s.fs.Stat(dir); err == nil {
+		return fmt.Errorf("create profile %q: %w", name, ErrProfileExists)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("create profile %q: %w", name, err)
+	}
+	if err := s.fs.MkdirAll(dir, s.dirMode); err != nil {
+		return fmt.Errorf("create profile %q: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteProfile removes the profile name and every save in it. The profile
+// in use cannot be deleted.
+func (s *SaveManager) DeleteProfile(name string) error {
+	if err := validateSaveName(name); err != nil {
+		return err
+	}
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	if err := s.initProfiles(); err != nil {
+		return fmt.Errorf("delete profile %q: %w", name, err)
+	}
+	if name == s.CurrentProfile() {
+		return fmt.Errorf("delete profile %q: %w", name, ErrProfileActive)
+	}
+	dir := s.profileDir(name)
+	if _, err := s.fs.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete profile %q: %w", name, ErrProfileNotFound)
+	} else if err != nil {
+		return fmt.Errorf("delete profile %q: %w", name, err)
+	}
+	if err := s.fs.RemoveAll(dir); err != nil {
+		return fmt.Errorf("delete profile %q: %w", name, err)
+	}
+	return nil
+}
+
+func (s *SaveManager) profileDir(name string) string {
+	return filepath.Join(s.root(), profilesDir, name)
+}
+
+// restoreProfile picks up the profiles of a data directory that has them,
+// switching to the profile in use when the manager was last run.
+func (s *SaveManager) restoreProfile() error {
+	fi, err := s.fs.Stat(filepath.Join(s.root(), profilesDir))
+	if errors.Is(err, os.ErrNotExist) || err == nil && !fi.IsDir() {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.initProfiles()
+}
+
+// initProfiles sets profiles up on first use: the data directory becomes
+// the root of the profiles, the files in it move into the default profile,
+// and the manager switches to the profile last in use, or the default one.
+// A move interrupted part way is completed the next time. Saves the default
+// profile already holds are left in the root directory, as moveDataFiles
+// describes.
+func (s *SaveManager) initProfiles() error {
+	if s.CurrentProfile() != "" {
+		return nil
+	}
+	dir := s.profileDir(defaultProfile)
+	if err := s.fs.MkdirAll(dir, s.dirMode); err != nil {
+		return err
+	}
+	files, err := s.dataFiles(s.root(), "")
+	if err != nil {
+		return err
+	}
+	files = slices.DeleteFunc(files, func(rel string) bool {
+		return strings.HasPrefix(rel, profilesDir+string(filepath.Separator))
+	})
+	if err := s.moveDataFiles(s.root(), dir, files); err != nil {
+		return fmt.Errorf("move saves into profile %q: %w", defaultProfile, err)
+	}
+	return s.useProfile(s.activeProfile())
+}
+
+// profileFileOwner returns the save the file rel under the root directory
+// belongs to, or rel itself for files that belong to no save, such as the
+// last-save marker.
+func (s *SaveManager) profileFileOwner(rel string) string {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) == 1 {
+		if name, ok := s.trimSaveExt(rel); ok {
+			return name
+		}
+		if name, ok := trimSidecarExt(rel); ok {
+			return name
+		}
+		return rel
+	}
+	switch parts[0] {
+	case "backups", "bundles", "deltas":
+		return parts[1]
+	}
+	return rel
+}
+
+func (s *SaveManager) activeProfilePath() string {
+	return filepath.Join(s.root(), profilesDir, activeProfileFile)
+}
+
+// activeProfile returns the profile recorded as in use, or the default
+// profile if none is or it no longer exists.
+func (s *SaveManager) activeProfile() string {
+	data, err := readFile(s.fs, s.activeProfilePath())
+	if err != nil {
+		return defaultProfile
+	}
+	name := strings.TrimSpace(string(data))
+	if validateSaveName(name) != nil {
+		return defaultProfile
+	}
+	if fi, err := s.fs.Stat(s.profileDir(name)); err != nil || !fi.IsDir() {
+		return defaultProfile
+	}
+	return name
+}
+
+// useProfile switches the data directory to the profile name and records it
+// as the one in use, forgetting what the manager remembers about the saves
+// of the previous one.
+func (s *SaveManager) useProfile(name string) error {
+	err := s.writeFileAtomic(s.activeProfilePath(), s.fileMode, func(w io.Writer) error {
+		_, err := io.WriteString(w, name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	dir := s.profileDir(name)
+	s.dirMu.Lock()
+	s.profile = name
+	s.dataDir = dir
+	s.dirMu.Unlock()
+	s.ClearCache()
+	s.backupCountsMu.Lock()
+	s.backupCounts = nil
+	s.backupCountsMu.Unlock()
+	return nil
+}
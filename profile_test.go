@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProfilesAreIsolated(t *testing.T) {
+	s, root := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2}`)
+	if err := s.SetLastSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first profile moves the existing saves into "default".
+	if got := s.CurrentProfile(); got != defaultProfile {
+		t.Fatalf("CurrentProfile = %q, want %q", got, defaultProfile)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame after migration = %s", got)
+	}
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 1 {
+		t.Errorf("ListBackups after migration = %v, %v, want one backup", ids, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "farm"+defaultSaveExt)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("save left in the root directory: %v", err)
+	}
+
+	if err := s.SwitchProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := s.GetAllSaves(); err != nil || len(names) != 0 {
+		t.Errorf("GetAllSaves in new profile = %v, %v, want none", names, err)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "" {
+		t.Errorf("GetLastSave in new profile = %q, %v, want none", last, err)
+	}
+	mustSave(t, s, "kid farm", `{}`)
+
+	if err := s.SwitchProfile(defaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := s.GetAllSaves(); err != nil || !slices.Equal(names, []string{"farm"}) {
+		t.Errorf("GetAllSaves in default profile = %v, %v, want [farm]", names, err)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "farm" {
+		t.Errorf("GetLastSave in default profile = %q, %v, want farm", last, err)
+	}
+	if profiles, err := s.ListProfiles(); err != nil || !slices.Equal(profiles, []string{defaultProfile, "kid"}) {
+		t.Errorf("ListProfiles = %v, %v", profiles, err)
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	s, _ := newTestManager(t)
+	if err := s.CreateProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateProfile("kid"); !errors.Is(err, ErrProfileExists) {
+		t.Errorf("CreateProfile of an existing profile = %v, want ErrProfileExists", err)
+	}
+	if err := s.SwitchProfile("nobody"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("SwitchProfile of a missing profile = %v, want ErrProfileNotFound", err)
+	}
+	if err := s.DeleteProfile("nobody"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("DeleteProfile of a missing profile = %v, want ErrProfileNotFound", err)
+	}
+	if err := s.DeleteProfile(defaultProfile); !errors.Is(err, ErrProfileActive) {
+		t.Errorf("DeleteProfile of the active profile = %v, want ErrProfileActive", err)
+	}
+	if err := s.DeleteProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	if profiles, err := s.ListProfiles(); err != nil || !slices.Equal(profiles, []string{defaultProfile}) {
+		t.Errorf("ListProfiles after delete = %v, %v", profiles, err)
+	}
+}
+
+func TestProfileRestoredOnRestart(t *testing.T) {
+	s, root := newTestManager(t)
+	if err := s.CreateProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SwitchProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSaveManagerWithDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.CurrentProfile(); got != "kid" {
+		t.Fatalf("CurrentProfile after restart = %q, want kid", got)
+	}
+	mustSave(t, s, "farm", `{}`)
+	if _, err := os.Stat(filepath.Join(root, "profiles", "kid", "farm"+defaultSaveExt)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProfileMigrationClash(t *testing.T) {
+	s, root := newTestManager(t)
+	if err := s.CreateProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+	// Saves dropped into the root by an older version of the game.
+	if err := os.WriteFile(filepath.Join(root, "farm"+defaultSaveExt), []byte(`{"day":2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "ranch"+defaultSaveExt), []byte(`{"day":3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSaveManagerWithDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("clashing save overwrote the profile's: %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "farm"+defaultSaveExt)); err != nil {
+		t.Errorf("clashing save not left in the root: %v", err)
+	}
+	if got := mustLoad(t, s, "ranch"); got != `{"day":3}` {
+		t.Errorf("LoadGame of moved save = %s", got)
+	}
+}
+
+func TestMigrateDataDirKeepsProfiles(t *testing.T) {
+	s, _ := newTestManager(t)
+	if err := s.CreateProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{}`)
+	if err := s.SwitchProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "kid farm", `{}`)
+
+	newRoot := filepath.Join(t.TempDir(), "saves")
+	if err := s.MigrateDataDir(newRoot, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.DataDir(), filepath.Join(newRoot, "profiles", "kid"); got != want {
+		t.Errorf("DataDir = %s, want %s", got, want)
+	}
+	if names, err := s.GetAllSaves(); err != nil || !slices.Equal(names, []string{"kid farm"}) {
+		t.Errorf("GetAllSaves = %v, %v, want [kid farm]", names, err)
+	}
+	if err := s.SwitchProfile(defaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := s.GetAllSaves(); err != nil || !slices.Equal(names, []string{"farm"}) {
+		t.Errorf("GetAllSaves in default profile = %v, %v, want [farm]", names, err)
+	}
+}
+
+func TestSwitchProfileWhileSaving(t *testing.T) {
+	s, _ := newTestManager(t)
+	if err := s.CreateProfile("kid"); err != nil {
+		t.Fatal(err)
+	}
+	stop := s.StartAutosave("farm", time.Millisecond, func() string { return `{}` })
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			s.SaveGame("farm", `{}`)
+			s.GetAllSaves()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if err := s.SwitchProfile([]string{"kid", defaultProfile}[i%2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	stop()
+	// Every save landed whole in one profile or the other.
+	for _, profile := range []string{"kid", defaultProfile} {
+		if err := s.SwitchProfile(profile); err != nil {
+			t.Fatal(err)
+		}
+		names, err := s.GetAllSaves()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, saveName := range names {
+			if got, err := s.LoadGame(saveName); err != nil || got != `{}` {
+				t.Errorf("profile %s: LoadGame(%q) = %s, %v", profile, saveName, got, err)
+			}
+		}
+	}
+}
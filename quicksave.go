@@ -0,0 +1,17 @@
+package main
+
+// quickSaveSlot is the save QuickSave and QuickLoad use. GetAllSaves leaves it
+// out and GetAllSaveInfos flags it.
+const quickSaveSlot = "quicksave"
+
+// QuickSave writes saveData to the quick-save slot, replacing any previous
+// quick-save.
+func (s *SaveManager) QuickSave(saveData string) error {
+	return s.SaveGame(quickSaveSlot, saveData)
+}
+
+// QuickLoad returns the last quick-save, or an error wrapping ErrSaveNotFound
+// if there is none.
+func (s *SaveManager) QuickLoad() (string, error) {
+	return s.LoadGame(quickSaveSlot)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestQuickSaveRoundTrip(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.QuickSave(`{"day":4}`); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.QuickLoad()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"day":4}` {
+		t.Errorf("QuickLoad = %s", got)
+	}
+	names, err := s.GetAllSaves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"farm"}) {
+		t.Errorf("GetAllSaves = %v, want the quick save left out", names)
+	}
+}
+
+func TestQuickLoadWithoutQuickSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	if _, err := s.QuickLoad(); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("QuickLoad = %v, want ErrSaveNotFound", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadOnlySaveRefusesChanges(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "golden", `{"day":1}`)
+	if err := s.SetReadOnly("golden", true); err != nil {
+		t.Fatal(err)
+	}
+	if readOnly, err := s.IsReadOnly("golden"); err != nil || !readOnly {
+		t.Fatalf("IsReadOnly = %v, %v, want true", readOnly, err)
+	}
+	for name, change := range map[string]func() error{
+		"SaveGame":   func() error { return s.SaveGame("golden", `{"day":2}`) },
+		"SaveDelta":  func() error { return s.SaveDelta("golden", `{"day":1}`, `{"day":3}`) },
+		"DeleteSave": func() error { return s.DeleteSave("golden") },
+		"RenameSave": func() error { return s.RenameSave("golden", "renamed") },
+	} {
+		if err := change(); !errors.Is(err, ErrSaveReadOnly) {
+			t.Errorf("%s of a read-only save = %v, want ErrSaveReadOnly", name, err)
+		}
+	}
+	if got := mustLoad(t, s, "golden"); got != `{"day":1}` {
+		t.Errorf("read-only save changed to %s", got)
+	}
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || !infos[0].ReadOnly {
+		t.Errorf("GetAllSaveInfos = %+v, want the save marked read-only", infos)
+	}
+
+	// A copy is an ordinary save.
+	if err := s.DuplicateSave("golden", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	if readOnly, err := s.IsReadOnly("copy"); err != nil || readOnly {
+		t.Errorf("IsReadOnly(%q) = %v, %v, want false", "copy", readOnly, err)
+	}
+
+	if err := s.SetReadOnly("golden", false); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "golden", `{"day":2}`)
+	if err := s.DeleteSave("golden"); err != nil {
+		t.Errorf("DeleteSave after clearing read-only: %v", err)
+	}
+}
+
+func TestSetReadOnlyMissingSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	if err := s.SetReadOnly("nobody", true); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("SetReadOnly of a missing save = %v, want ErrSaveNotFound", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrNoValidBackup is returned by RepairSave when a save is corrupt and none
+// of its backups can replace it.
+var ErrNoValidBackup = errors.New("no valid backup")
+
+// RepairSave checks saveName and, if it fails its checksum or does not hold
+// valid JSON, replaces it with the most recent backup that does. The corrupt
+// file is kept as saveName+".corrupt". It returns nil without changing
+// anything if the save is intact, and an error wrapping both ErrNoValidBackup
+// and the problem found if there is no backup to restore.
+func (s *SaveManager) RepairSave(saveName string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	filename, err := s.savePath(saveName)
+	if err != nil {
+		return fmt.Errorf("repair save %q: %w", saveName, err)
+	}
+	problem := s.checkSave(saveName)
+	if problem == nil {
+		return nil
+	}
+	if !isCorruption(problem) {
+		return fmt.Errorf("repair save %q: %w", saveName, problem)
+	}
+
+	corrupt := filepath.Join(s.dir(), saveName+".corrupt")
+	if err := s.copyFileAtomic(filename, corrupt, s.fileMode); err != nil {
+		return fmt.Errorf("repair save %q: %w", saveName, err)
+	}
+	ids, err := s.backupIDs(saveName)
+	if err != nil {
+		return fmt.Errorf("repair save %q: %w", saveName, err)
+	}
+	for i := len(ids) - 1; i >= 0; i-- {
+		data, ext, header, err := s.readBackup(saveName, ids[i])
+		if err != nil || !s.validBackup(saveName+ext, data) {
+			continue
+		}
+		if err := s.installBackup(saveName, data, ext, header); err != nil {
+			return fmt.Errorf("repair save %q from backup %q: %w", saveName, ids[i], err)
+		}
+		return nil
+	}
+	return fmt.Errorf("repair save %q: %w: %w", saveName, ErrNoValidBackup, problem)
+}
+
+// checkSave reads saveName with its deltas applied and reports why it cannot
+// be loaded, or nil if it can. The caller must hold the save's lock.
+func (s *SaveManager) checkSave(saveName string) error {
+	saveData, _, deltas, err := s.readSaveAndDeltas(context.Background(), saveName)
+	if err == nil {
+		saveData, err = s.applyDeltas(saveData, deltas)
+	}
+	if err == nil && !json.Valid([]byte(saveData)) {
+		err = ErrInvalidSaveData
+	}
+	return err
+}
+
+// isCorruption reports whether err, returned by checkSave, means the save's
+// contents are damaged rather than that it could not be read at all.
+func isCorruption(err error) bool {
+	var pathErr *fs.PathError
+	switch {
+	case errors.Is(err, ErrDecryptionFailed), errors.Is(err, os.ErrNotExist):
+		// A wrong passphrase is not fixed by restoring a backup.
+		return false
+	case errors.As(err, &pathErr):
+		return false
+	}
+	return true
+}
+
+// validBackup reports whether data, the raw contents of the backup file
+// filename, decodes to valid JSON.
+func (s *SaveManager) validBackup(filename string, data []byte) bool {
+	r, err := s.decodeFile(filename, data)
+	if err != nil {
+		return false
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return false
+	}
+	plaintext, err = decodeSave(s.codecFor(filename), plaintext)
+	return err == nil && json.Valid(plaintext)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepairSaveRestoresBackup(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2}`)
+	if err := s.RepairSave("farm"); err != nil {
+		t.Errorf("RepairSave of a good save: %v", err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Fatalf("RepairSave changed a good save to %s", got)
+	}
+
+	corrupt := []byte(`{"day":2`)
+	if err := os.WriteFile(filepath.Join(dir, "farm"+defaultSaveExt), corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RepairSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame after repair = %s, want the backup", got)
+	}
+	kept, err := os.ReadFile(filepath.Join(dir, "farm.corrupt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kept) != string(corrupt) {
+		t.Errorf("farm.corrupt holds %q, want the corrupt save", kept)
+	}
+}
+
+func TestRepairSaveWithoutBackup(t *testing.T) {
+	s, dir := newTestManager(t)
+	if err := s.RepairSave("missing"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("RepairSave(missing) = %v, want ErrSaveNotFound", err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+	if err := os.WriteFile(filepath.Join(dir, "farm"+defaultSaveExt), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := s.RepairSave("farm")
+	if !errors.Is(err, ErrNoValidBackup) || !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("RepairSave = %v, want ErrNoValidBackup for a checksum mismatch", err)
+	}
+}
@@ -1,69 +1,1573 @@
 package main
 
 import (
-	"io/ioutil"
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
+const (
+	defaultMaxBackups = 5
+	defaultFileMode   = 0644
+	defaultDirMode    = 0755
+
+	defaultSaveExt = ".json"
+	lastSaveFile   = ".last_save"
+
+	// gzExt is appended to the save extension of compressed saves.
+	gzExt = ".gz"
+
+	// tmpExt marks files being written by writeFileAtomic.
+	tmpExt = ".tmp"
+)
+
+var (
+	// ErrSaveNotFound is returned when the requested save does not exist.
+	ErrSaveNotFound = errors.New("save not found")
+	// ErrSaveLimitReached is returned when creating a save would exceed the
+	// limit set with WithMaxSaves.
+	ErrSaveLimitReached = errors.New("save limit reached")
+	// ErrSaveExists is returned when an operation would overwrite another save.
+	ErrSaveExists = errors.New("save already exists")
+	// ErrInvalidSaveName is returned for save names that are empty or could
+	// escape the data directory or not be created on every platform.
+	ErrInvalidSaveName = errors.New("invalid save name")
+	// ErrInvalidSaveData is returned by SaveGame for data that is not valid
+	// JSON.
+	ErrInvalidSaveData = errors.New("save data is not valid JSON")
+	// ErrEmptySave is returned when loading a save whose file is empty.
+	ErrEmptySave = errors.New("save is empty")
+)
+
+// sidecarExts lists the extensions of the files kept alongside each save.
+var sidecarExts = []string{".header", ".sha256", ".png", ".meta", ".corrupt", ".history", ".tags", ".sync", ".ready", ".readonly", ".expires"}
+
 type SaveManager struct {
+	saveExt         string
+	compressedExt   string
+	codec           Codec
+	fs              FileSystem
+	maxBackups      int
+	compress        bool
+	maxSaves        int
+	pretty          bool
+	sortKeys        bool
+	slotCount       int
+	deltaCompaction int
+	historyLimit    int
+	durable         bool
+	maxSaveSize     int64
+	normalizeEOL    bool
+	fileLocking     bool
+	fileMode        os.FileMode
+	dirMode         os.FileMode
+	passphrase      string
+	keys            keyCache
+	cache           saveCache
+	store           Store
+	syncer          Syncer
+	gameVersion     string
+	nowFunc         func() time.Time
+	errorHandler    func(err error)
+	requiredFields  []string
+
+	// dataDirFallback holds why NewSaveManager passed over the platform's
+	// data directory, or nil.
+	dataDirFallback error
+
+	// formats lists the formats saves are read in, the one they are
+	// written in first. fileExts holds their file extensions, compressed
+	// and not, in the order savePath prefers them, and jsonExt the one of
+	// the JSON format, which internal records such as deltas use.
+	formats  []saveFormat
+	fileExts []string
+	jsonExt  string
+
+	autosaveRetention AutosaveRetention
+	backupPolicy      BackupPolicy
+
+	// dirMu guards dataDir, the directory the saves of the profile in use
+	// are kept in, rootDir, the data directory given at construction or
+	// set by MigrateDataDir, and profile, the profile in use if any.
+	dirMu   sync.RWMutex
 	dataDir string
+	rootDir string
+	profile string
+	// profileMu serialises changes to the profiles and data directory.
+	profileMu sync.Mutex
+
+	// mu guards migrations.
+	mu         sync.RWMutex
+	migrations map[int]migration
+
+	// createMu serialises creating new saves while a save limit is set.
+	createMu sync.Mutex
+	// newNameMu serialises SaveGameNewName, so that two calls cannot pick
+	// the same free name.
+	newNameMu sync.Mutex
+
+	// locksMu guards locks, which holds one lock per save name so that
+	// operations on different saves never block each other.
+	locksMu sync.Mutex
+	locks   map[string]*sync.RWMutex
+
+	// fileLocksMu guards fileLocks, the OS locks taken with LockSave.
+	fileLocksMu sync.Mutex
+	fileLocks   map[string]*os.File
+
+	// asyncMu guards asyncTail, which holds for each save a channel closed
+	// when its most recently queued SaveGameAsync finishes.
+	asyncMu   sync.Mutex
+	asyncTail map[string]chan struct{}
+
+	// backupCountsMu guards backupCounts, which holds for each save how
+	// many overwrites are left before BackupEvery backs it up again.
+	backupCountsMu sync.Mutex
+	backupCounts   map[string]int
+
+	// hooksMu guards the hooks registered with OnSave and OnLoad.
+	hooksMu    sync.RWMutex
+	saveHooks  []func(saveName string, size int64)
+	loadHooks  []func(saveName string)
+	newerHooks []func(saveName, gameVersion string)
 }
 
-func NewSaveManager() *SaveManager {
-	homeDir, _ := os.UserHomeDir()
-	dataDir := filepath.Join(homeDir, ".valley-legend", "data")
-	err := os.MkdirAll(dataDir, 0755)
-	if err != nil {
-		return nil
+// Option configures a SaveManager at construction time.
+type Option func(*SaveManager)
+
+// WithMaxBackups sets how many backups are kept per save. Zero disables
+// backups entirely.
+func WithMaxBackups(n int) Option {
+	return func(s *SaveManager) {
+		s.maxBackups = n
 	}
-	return &SaveManager{dataDir: dataDir}
 }
 
+// WithCompression makes SaveGame write gzip-compressed saves to
+// saveName+".json.gz". Uncompressed saves keep loading either way.
+func WithCompression(enabled bool) Option {
+	return func(s *SaveManager) {
+		s.compress = enabled
+	}
+}
+
+// WithMaxSaves limits the number of saves SaveGame will create. Overwriting
+// an existing save is always allowed, and autosaves and the quick-save do not
+// count.
+func WithMaxSaves(n int) Option {
+	return func(s *SaveManager) {
+		s.maxSaves = n
+	}
+}
+
+// WithPrettyPrint makes SaveGame indent saves for reading and diffing instead
+// of compacting them.
+func WithPrettyPrint(enabled bool) Option {
+	return func(s *SaveManager) {
+		s.pretty = enabled
+	}
+}
+
+// WithSortedKeys makes SaveGame write object keys in sorted order, so that
+// the same state always produces the same bytes whatever order the game
+// wrote its keys in. A key repeated within an object keeps its last value.
+func WithSortedKeys(enabled bool) Option {
+	return func(s *SaveManager) {
+		s.sortKeys = enabled
+	}
+}
+
+// WithDurableWrites makes every write fsync the file before it replaces the
+// old one and the directory afterwards, so a completed save survives a power
+// loss. It slows down frequent saves such as autosaves.
+func WithDurableWrites(enabled bool) Option {
+	return func(s *SaveManager) {
+		s.durable = enabled
+	}
+}
+
+// WithNormalizeLineEndings makes LoadGame convert CRLF line endings to LF, for
+// saves that were edited by hand on Windows.
+func WithNormalizeLineEndings(enabled bool) Option {
+	return func(s *SaveManager) {
+		s.normalizeEOL = enabled
+	}
+}
+
+// WithSaveExt sets the extension of the save files, ".json" by default, so
+// that other kinds of saves, such as ".config" profiles, can be kept by their
+// own SaveManager. Files with other extensions are ignored. Sidecars, backups
+// and the last-save marker are keyed by save name alone, so managers sharing
+// a directory must not use the same names.
+func WithSaveExt(ext string) Option {
+	return func(s *SaveManager) {
+		s.saveExt = ext
+	}
+}
+
+// WithFileMode sets the permissions of the files SaveManager writes, such as
+// 0600 to keep saves private on a shared machine.
+func WithFileMode(mode os.FileMode) Option {
+	return func(s *SaveManager) {
+		s.fileMode = mode
+	}
+}
+
+// WithDirMode sets the permissions of the directories SaveManager creates.
+func WithDirMode(mode os.FileMode) Option {
+	return func(s *SaveManager) {
+		s.dirMode = mode
+	}
+}
+
+// NewSaveManagerWithDir returns a SaveManager that keeps its saves in dir,
+// creating the directory if needed.
+func NewSaveManagerWithDir(dir string, opts ...Option) (*SaveManager, error) {
+	s := &SaveManager{
+		dataDir:         dir,
+		rootDir:         dir,
+		saveExt:         defaultSaveExt,
+		codec:           JSONCodec{},
+		nowFunc:         time.Now,
+		fs:              osFileSystem{},
+		maxBackups:      defaultMaxBackups,
+		slotCount:       defaultSlotCount,
+		deltaCompaction: defaultDeltaCompaction,
+		historyLimit:    defaultHistoryLimit,
+		fileMode:        defaultFileMode,
+		dirMode:         defaultDirMode,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.initFormats()
+	if s.store == nil {
+		s.store = &FSStore{m: s}
+	}
+	if err := s.fs.MkdirAll(dir, s.dirMode); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	if err := s.restoreProfile(); err != nil {
+		return nil, fmt.Errorf("restore profile: %w", err)
+	}
+	return s, nil
+}
+
+// saveLock returns the lock guarding saveName, creating it on first use.
+func (s *SaveManager) saveLock(saveName string) *sync.RWMutex {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	if s.locks == nil {
+		s.locks = make(map[string]*sync.RWMutex)
+	}
+	mu, ok := s.locks[saveName]
+	if !ok {
+		mu = &sync.RWMutex{}
+		s.locks[saveName] = mu
+	}
+	return mu
+}
+
+// dir returns the directory the saves of the profile in use are kept in.
+func (s *SaveManager) dir() string {
+	s.dirMu.RLock()
+	defer s.dirMu.RUnlock()
+	return s.dataDir
+}
+
+// root returns the data directory, which holds the profiles if they are
+// used.
+func (s *SaveManager) root() string {
+	s.dirMu.RLock()
+	defer s.dirMu.RUnlock()
+	return s.rootDir
+}
+
+// SaveGame writes saveData, which must be JSON, to saveName. The save is
+// replaced atomically, so a crash leaves either the old or the new save.
+// Surviving a power loss as well needs WithDurableWrites.
 func (s *SaveManager) SaveGame(saveName string, saveData string) error {
-	filename := filepath.Join(s.dataDir, saveName+".json")
-	return ioutil.WriteFile(filename, []byte(saveData), 0644)
+	return s.SaveGameContext(context.Background(), saveName, saveData)
+}
+
+// SaveGameContext is SaveGame with cancellation. The data is written in chunks
+// and the write is abandoned, leaving the previous save intact, as soon as ctx
+// is done.
+func (s *SaveManager) SaveGameContext(ctx context.Context, saveName string, saveData string) error {
+	_, err := s.saveGameResult(ctx, saveName, saveData)
+	return err
+}
+
+// SaveGameResult describes a completed save.
+type SaveGameResult struct {
+	// Bytes is the size of the save file, or of the data for saves kept
+	// outside the data directory with WithStore.
+	Bytes int64 `json:"bytes"`
+	// Path is the save file, or "" for saves kept with WithStore.
+	Path       string        `json:"path"`
+	Duration   time.Duration `json:"duration"`
+	Compressed bool          `json:"compressed"`
+}
+
+// SaveGameWithResult is SaveGame that also reports how the save went, e.g.
+// for telemetry.
+func (s *SaveManager) SaveGameWithResult(saveName string, saveData string) (SaveGameResult, error) {
+	return s.saveGameResult(context.Background(), saveName, saveData)
+}
+
+// SaveGameNewName saves saveData like SaveGame under baseName or, if a save
+// by that name exists, under the first free one of "baseName (2)",
+// "baseName (3)" and so on, and returns the name it used. It is for keeping
+// both saves when the player saves over an existing one.
+func (s *SaveManager) SaveGameNewName(baseName, saveData string) (finalName string, err error) {
+	if err := validateSaveName(baseName); err != nil {
+		return "", err
+	}
+	s.newNameMu.Lock()
+	defer s.newNameMu.Unlock()
+	saveName := baseName
+	for n := 2; ; n++ {
+		exists, err := s.HasSave(saveName)
+		if err != nil {
+			return "", fmt.Errorf("save %q: %w", saveName, err)
+		}
+		if !exists {
+			break
+		}
+		saveName = fmt.Sprintf("%s (%d)", baseName, n)
+	}
+	if err := s.SaveGame(saveName, saveData); err != nil {
+		return "", err
+	}
+	return saveName, nil
+}
+
+func (s *SaveManager) saveGameResult(ctx context.Context, saveName string, saveData string) (SaveGameResult, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return SaveGameResult{}, err
+	}
+	saveData, err := s.formatSaveData(saveData)
+	if err != nil {
+		return SaveGameResult{}, fmt.Errorf("save %q: %w", saveName, err)
+	}
+	start := time.Now()
+	if err := s.storeSave(ctx, saveName, saveData); err != nil {
+		return SaveGameResult{}, err
+	}
+	result := SaveGameResult{Bytes: int64(len(saveData)), Duration: time.Since(start)}
+	if _, ok := s.store.(*FSStore); ok {
+		mu := s.saveLock(saveName)
+		mu.RLock()
+		if filename, err := s.savePath(saveName); err == nil {
+			result.Path = filename
+			result.Compressed = strings.HasSuffix(filename, gzExt)
+			if fi, err := s.fs.Stat(filename); err == nil {
+				result.Bytes = fi.Size()
+			}
+		}
+		mu.RUnlock()
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return result, nil
+}
+
+// SaveGameRaw saves saveData exactly as given, without the JSON check and
+// formatting SaveGame applies, for callers that need to store other data.
+// With a codec other than JSONCodec, saveData must still be JSON so that it
+// can be converted.
+func (s *SaveManager) SaveGameRaw(saveName string, saveData string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	if err := s.storeSave(context.Background(), saveName, saveData); err != nil {
+		return err
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return nil
+}
+
+// formatSaveData compacts saveData, or indents it if WithPrettyPrint is set,
+// after sorting its keys if WithSortedKeys is. It fails with
+// ErrInvalidSaveData if saveData is not valid JSON.
+func (s *SaveManager) formatSaveData(saveData string) (string, error) {
+	if !json.Valid([]byte(saveData)) {
+		return "", ErrInvalidSaveData
+	}
+	if s.sortKeys {
+		state, err := decodeState(saveData)
+		if err != nil {
+			return "", err
+		}
+		sorted, err := encodeState(state)
+		if err != nil {
+			return "", err
+		}
+		saveData = string(sorted)
+	}
+	var buf bytes.Buffer
+	var err error
+	if s.pretty {
+		err = json.Indent(&buf, []byte(saveData), "", "  ")
+	} else {
+		err = json.Compact(&buf, []byte(saveData))
+	}
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (s *SaveManager) saveGame(ctx context.Context, saveName string, saveData string) error {
+	if err := s.checkSaveSize(int64(len(saveData))); err != nil {
+		return err
+	}
+	return s.saveGameFrom(ctx, saveName, strings.NewReader(saveData))
+}
+
+// saveGameFrom writes the save data read from r to saveName. The caller must
+// hold the save's write lock.
+func (s *SaveManager) saveGameFrom(ctx context.Context, saveName string, r io.Reader) error {
+	// The data directory may have been deleted while the game was running.
+	if err := s.fs.MkdirAll(s.dir(), s.dirMode); err != nil {
+		return err
+	}
+	release, err := s.holdFileLock(saveName)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if s.maxSaveSize > 0 {
+		r = &sizeLimitedReader{s: s, r: r}
+	}
+	cr := &countingReader{r: r}
+	err = s.writeSave(ctx, saveName, cr)
+	if isNoSpace(err) {
+		return fmt.Errorf("%w: %w", ErrInsufficientSpace, err)
+	}
+	if err != nil {
+		return err
+	}
+	s.recordHistory(saveName, cr.n, historyNote(ctx))
+	return nil
+}
+
+func (s *SaveManager) writeSave(ctx context.Context, saveName string, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.checkNotReadOnly(saveName); err != nil {
+		return err
+	}
+	if s.maxSaves > 0 && countsTowardLimit(saveName) {
+		// Hold createMu from the count until the write so that two new
+		// saves cannot both squeeze in under the limit.
+		s.createMu.Lock()
+		defer s.createMu.Unlock()
+		if err := s.checkSaveLimit(saveName); err != nil {
+			return err
+		}
+	}
+	if err := s.backupSaveIfDue(saveName); err != nil {
+		return err
+	}
+	s.uncacheSave(saveName)
+	if !isJSONCodec(s.codec) {
+		saveData, err := readAllContext(ctx, r, 0)
+		if err != nil {
+			return err
+		}
+		encoded, err := encodeSave(s.codec, saveData)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(encoded)
+	}
+	ext := s.saveExt
+	if s.compress {
+		ext = s.compressedExt
+	}
+	sum := sha256.New()
+	err := s.writeFileAtomic(filepath.Join(s.dir(), saveName+ext), s.fileMode, func(f io.Writer) error {
+		if s.maxSaveSize > 0 {
+			f = &sizeLimitedWriter{s: s, w: f}
+		}
+		w := io.MultiWriter(f, sum)
+		if s.passphrase != "" {
+			plaintext, err := readAllContext(ctx, r, 0)
+			if err != nil {
+				return err
+			}
+			sealed, err := s.sealSave(plaintext)
+			if err != nil {
+				return err
+			}
+			return copyContext(ctx, w, bytes.NewReader(sealed))
+		}
+		if !s.compress {
+			return copyContext(ctx, w, r)
+		}
+		zw := gzip.NewWriter(w)
+		if err := copyContext(ctx, zw, r); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		return err
+	}
+	// Drop the variants in other formats so the save isn't listed twice.
+	if err := s.removeOtherVariants(saveName, ext); err != nil {
+		return err
+	}
+	if err := s.writeChecksum(saveName, sum.Sum(nil)); err != nil {
+		return err
+	}
+	if err := s.writeHeader(saveName, saveHeader{Version: s.currentVersion(), GameVersion: s.gameVersion}); err != nil {
+		return err
+	}
+	// A full save supersedes any deltas.
+	if err := s.fs.RemoveAll(s.deltaDir(saveName)); err != nil {
+		return err
+	}
+	return s.bumpGeneration(saveName)
 }
 
 func (s *SaveManager) LoadGame(saveName string) (string, error) {
-	filename := filepath.Join(s.dataDir, saveName+".json")
-	data, err := ioutil.ReadFile(filename)
+	return s.LoadGameContext(context.Background(), saveName)
+}
+
+// LoadGameContext is LoadGame with cancellation. The file is read in chunks
+// and the load fails with ctx.Err() as soon as ctx is done.
+func (s *SaveManager) LoadGameContext(ctx context.Context, saveName string) (string, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return "", err
+	}
+	saveData, err := s.store.Load(ctx, saveName)
+	if err == nil {
+		err = s.checkSchema(saveData)
+	}
+	if err != nil {
+		return "", fmt.Errorf("load save %q: %w", saveName, err)
+	}
+	s.notifyLoad(saveName)
+	return saveData, nil
+}
+
+func (s *SaveManager) loadGame(ctx context.Context, saveName string) (string, error) {
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	if saveData, ok := s.cachedSave(saveName); ok {
+		mu.RUnlock()
+		return saveData, nil
+	}
+	saveData, version, deltas, err := s.readSaveAndDeltas(ctx, saveName)
+	if err != nil {
+		mu.RUnlock()
+		return "", err
+	}
+	if version >= s.currentVersion() {
+		defer mu.RUnlock()
+		saveData, err = s.applyDeltas(saveData, deltas)
+		if err != nil {
+			return "", err
+		}
+		s.cacheSave(saveName, saveData)
+		return saveData, nil
+	}
+	mu.RUnlock()
+
+	// Upgrading rewrites the save, so retake the lock for writing and read
+	// again in case another writer got in first.
+	mu.Lock()
+	defer mu.Unlock()
+	return s.loadGameLocked(ctx, saveName)
+}
+
+// loadGameLocked is loadGame for a caller that already holds the save's write
+// lock.
+func (s *SaveManager) loadGameLocked(ctx context.Context, saveName string) (string, error) {
+	saveData, version, deltas, err := s.readSaveAndDeltas(ctx, saveName)
+	if err != nil {
+		return "", err
+	}
+	if version >= s.currentVersion() {
+		return s.applyDeltas(saveData, deltas)
+	}
+	saveData, err = s.migrate(saveData, version)
 	if err != nil {
 		return "", err
 	}
-	return string(data), nil
+	saveData, err = s.applyDeltas(saveData, deltas)
+	if err != nil {
+		return "", err
+	}
+	if readOnly, err := s.isReadOnly(saveName); err != nil || readOnly {
+		// Protected saves are migrated afresh on every load instead.
+		return saveData, err
+	}
+	if err := s.saveGame(ctx, saveName, saveData); err != nil {
+		return "", err
+	}
+	return saveData, nil
+}
+
+// readSaveAndDeltas is readSave that also returns the deltas stored on top
+// of the save.
+func (s *SaveManager) readSaveAndDeltas(ctx context.Context, saveName string) (string, int, []deltaRecord, error) {
+	saveData, version, err := s.readSave(ctx, saveName)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	deltas, err := s.readDeltas(saveName)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	return saveData, version, deltas, nil
+}
+
+// readSave returns the contents of saveName and the schema version it was
+// written with. A leading UTF-8 byte order mark is dropped, and CRLF line
+// endings are converted if WithNormalizeLineEndings is set.
+func (s *SaveManager) readSave(ctx context.Context, saveName string) (string, int, error) {
+	r, version, err := s.openSaveChecked(saveName, !skipChecksum(ctx))
+	if err != nil {
+		return "", 0, err
+	}
+	defer r.Close()
+	data, err := readAllContext(ctx, r, r.size)
+	if err != nil {
+		return "", 0, err
+	}
+	if !isJSONCodec(r.codec) {
+		data, err = decodeSave(r.codec, data)
+		if err != nil {
+			return "", 0, err
+		}
+		return string(data), version, nil
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if s.normalizeEOL {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+	return string(data), version, nil
+}
+
+// utf8BOM is the byte order mark some Windows editors put at the start of
+// UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// openSave opens saveName for reading, decrypting and decompressing it on the
+// fly, and returns it with the schema version it was written with. The
+// checksum is compared once the reader reaches the end of the file, where it
+// reports ErrChecksumMismatch instead of io.EOF if the save is corrupt.
+func (s *SaveManager) openSave(saveName string) (*saveReader, int, error) {
+	return s.openSaveChecked(saveName, true)
+}
+
+// openSaveChecked is openSave that skips the checksum unless verify is set.
+func (s *SaveManager) openSaveChecked(saveName string, verify bool) (*saveReader, int, error) {
+	filename, err := s.savePath(saveName)
+	if err != nil {
+		return nil, 0, err
+	}
+	header, err := s.readHeader(saveName)
+	if err != nil {
+		return nil, 0, err
+	}
+	var want []byte
+	if verify {
+		if want, err = s.readChecksum(saveName); err != nil {
+			return nil, 0, err
+		}
+	}
+	f, err := s.fs.Open(filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	sr := &saveReader{file: f, codec: s.codecFor(filename)}
+	if fi, err := f.Stat(); err == nil {
+		sr.size = fi.Size()
+		if sr.size == 0 {
+			f.Close()
+			return nil, 0, ErrEmptySave
+		}
+	}
+	var br *bufio.Reader
+	if verify {
+		br = bufio.NewReader(&verifyingReader{r: f, hash: sha256.New(), want: want})
+	} else {
+		br = bufio.NewReader(f)
+	}
+	sr.Reader = br
+	if magic, _ := br.Peek(len(encryptedMagic)); isEncrypted(magic) {
+		// AES-GCM only authenticates the whole ciphertext, so it has to be
+		// read in full before anything can be returned.
+		sealed, err := io.ReadAll(br)
+		if err == nil {
+			sr.Reader, err = s.unseal(filename, sealed)
+		}
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return sr, header.Version, nil
+	}
+	if strings.HasSuffix(filename, gzExt) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		sr.Reader = zr
+	}
+	return sr, header.Version, nil
+}
+
+// sealSave compresses plaintext if configured and encrypts it, for writing in
+// one piece since AES-GCM cannot be streamed.
+func (s *SaveManager) sealSave(plaintext []byte) ([]byte, error) {
+	if s.compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(plaintext); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		plaintext = buf.Bytes()
+	}
+	return s.encrypt(plaintext)
+}
+
+// unseal reverses sealSave for the encrypted contents of the save file
+// filename.
+func (s *SaveManager) unseal(filename string, sealed []byte) (io.Reader, error) {
+	plaintext, err := s.decrypt(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(filename, gzExt) {
+		return gzip.NewReader(bytes.NewReader(plaintext))
+	}
+	return bytes.NewReader(plaintext), nil
+}
+
+// decodeFile returns the plaintext of the raw contents data of the save file
+// filename, decrypting and decompressing it as needed.
+func (s *SaveManager) decodeFile(filename string, data []byte) (io.Reader, error) {
+	if isEncrypted(data) {
+		return s.unseal(filename, data)
+	}
+	if strings.HasSuffix(filename, gzExt) {
+		return gzip.NewReader(bytes.NewReader(data))
+	}
+	return bytes.NewReader(data), nil
+}
+
+// savePath returns the file holding saveName, preferring the written format
+// over the others and the compressed variant of each when several exist.
+func (s *SaveManager) savePath(saveName string) (string, error) {
+	var err error
+	for _, ext := range s.fileExts {
+		filename := filepath.Join(s.dir(), saveName+ext)
+		if _, err = s.fs.Stat(filename); err == nil || !errors.Is(err, os.ErrNotExist) {
+			return filename, err
+		}
+	}
+	return filepath.Join(s.dir(), saveName+s.saveExt), fmt.Errorf("%w: %w", ErrSaveNotFound, err)
+}
+
+// trimSaveExt strips the save file extension from filename, reporting false
+// if filename is not a save file.
+func (s *SaveManager) trimSaveExt(filename string) (string, bool) {
+	for _, ext := range s.fileExts {
+		if strings.HasSuffix(filename, ext) {
+			return strings.TrimSuffix(filename, ext), true
+		}
+	}
+	return "", false
+}
+
+// fileSaveExt returns the save extension filename ends with.
+func (s *SaveManager) fileSaveExt(filename string) string {
+	for _, ext := range s.fileExts {
+		if strings.HasSuffix(filename, ext) {
+			return ext
+		}
+	}
+	return s.saveExt
+}
+
+// removeOtherVariants removes the files of saveName in every format but the
+// one with extension keep.
+func (s *SaveManager) removeOtherVariants(saveName, keep string) error {
+	for _, ext := range s.fileExts {
+		if ext == keep {
+			continue
+		}
+		if err := s.fs.Remove(filepath.Join(s.dir(), saveName+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
 }
 
+// GetAllSaves returns the names of all saves except the quick-save. A data
+// directory that cannot be read is reported as holding no saves; use
+// GetAllSavesStrict to tell the two apart.
 func (s *SaveManager) GetAllSaves() ([]string, error) {
-	files, err := ioutil.ReadDir(s.dataDir)
+	saves, err := s.store.List()
 	if err != nil {
 		return []string{}, nil
 	}
+	return withoutQuickSave(saves), nil
+}
+
+// UnreadableSavesError is returned by GetAllSavesStrict, along with the saves
+// it could list, when some save files in the data directory could not be
+// stat'ed. Files names those files.
+type UnreadableSavesError struct {
+	Files []string
+}
+
+func (e *UnreadableSavesError) Error() string {
+	return fmt.Sprintf("cannot stat save files: %s", strings.Join(e.Files, ", "))
+}
+
+// GetAllSavesStrict is GetAllSaves, but returns the error if the data
+// directory cannot be read. A missing data directory holds no saves. Save
+// files that cannot be stat'ed are left out of the list, which is returned
+// together with an *UnreadableSavesError naming them.
+func (s *SaveManager) GetAllSavesStrict() ([]string, error) {
+	var saves, unreadable []string
+	var err error
+	if _, ok := s.store.(*FSStore); ok {
+		saves, unreadable, err = s.statSaveNames()
+	} else {
+		saves, err = s.store.List()
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list saves: %w", err)
+	}
+	list := withoutQuickSave(saves)
+	if len(unreadable) > 0 {
+		return list, &UnreadableSavesError{Files: unreadable}
+	}
+	return list, nil
+}
+
+// withoutQuickSave returns saves without the quick-save, which has its own
+// key binding and is kept out of the list.
+func withoutQuickSave(saves []string) []string {
+	list := []string{}
+	for _, name := range saves {
+		if name != quickSaveSlot {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// HasSave reports whether saveName exists, without reading it.
+func (s *SaveManager) HasSave(saveName string) (bool, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return false, err
+	}
+	if ec, ok := s.store.(existenceChecker); ok {
+		return ec.Exists(saveName)
+	}
+	names, err := s.store.List()
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(names, saveName), nil
+}
+
+// checkSaveLimit returns ErrSaveLimitReached if saveName would be a new save
+// beyond the limit set with WithMaxSaves.
+func (s *SaveManager) checkSaveLimit(saveName string) error {
+	if _, err := s.savePath(saveName); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	names, err := s.saveNames()
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, name := range names {
+		if countsTowardLimit(name) {
+			count++
+		}
+	}
+	if count >= s.maxSaves {
+		return fmt.Errorf("%w: at most %d saves allowed", ErrSaveLimitReached, s.maxSaves)
+	}
+	return nil
+}
+
+// countsTowardLimit reports whether saveName counts against WithMaxSaves.
+func countsTowardLimit(saveName string) bool {
+	return saveName != quickSaveSlot && !isAutosave(saveName)
+}
+
+// statSaveNames lists the names of all saves in the data directory like
+// saveNames, leaving out save files that cannot be stat'ed and returning
+// their file names separately.
+func (s *SaveManager) statSaveNames() (saves, unreadable []string, err error) {
+	files, err := s.fs.ReadDir(s.dir())
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		name, ok := s.trimSaveExt(file.Name())
+		if !ok {
+			continue
+		}
+		if _, err := file.Info(); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				unreadable = append(unreadable, file.Name())
+			}
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			saves = append(saves, name)
+		}
+	}
+	return saves, unreadable, nil
+}
+
+// saveNames lists the names of all saves in the data directory.
+func (s *SaveManager) saveNames() ([]string, error) {
+	files, err := s.fs.ReadDir(s.dir())
+	if err != nil {
+		return nil, err
+	}
 	var saves []string
+	seen := make(map[string]bool)
 	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
-			saves = append(saves, strings.TrimSuffix(file.Name(), ".json"))
+		if file.IsDir() {
+			continue
+		}
+		if name, ok := s.trimSaveExt(file.Name()); ok && !seen[name] {
+			seen[name] = true
+			saves = append(saves, name)
 		}
 	}
 	return saves, nil
 }
 
+// SaveInfo describes a save file on disk.
+type SaveInfo struct {
+	Name         string    `json:"name"`
+	ModTime      time.Time `json:"modTime"`
+	Size         int64     `json:"size"`
+	HasThumbnail bool      `json:"hasThumbnail"`
+	IsQuickSave  bool      `json:"isQuickSave"`
+	Tags         []string  `json:"tags"`
+	ReadOnly     bool      `json:"readOnly"`
+	// Temporary is set for saves written with SaveGameTemporary, which
+	// PurgeExpired deletes once ExpiresAt has passed.
+	Temporary bool      `json:"temporary"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// GetAllSaveInfos returns every save with its modification time and size,
+// most recently modified first and by name among saves modified together.
+func (s *SaveManager) GetAllSaveInfos() ([]SaveInfo, error) {
+	files, err := s.fs.ReadDir(s.dir())
+	if err != nil {
+		return nil, err
+	}
+	infos := []SaveInfo{}
+	seen := make(map[string]int)
+	ranks := make(map[string]int)
+	thumbnails := make(map[string]bool)
+	tagged := make(map[string]bool)
+	readOnly := make(map[string]bool)
+	temporary := make(map[string]bool)
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".png") {
+			thumbnails[strings.TrimSuffix(file.Name(), ".png")] = true
+		}
+		if strings.HasSuffix(file.Name(), ".tags") {
+			tagged[strings.TrimSuffix(file.Name(), ".tags")] = true
+		}
+		if strings.HasSuffix(file.Name(), ".readonly") {
+			readOnly[strings.TrimSuffix(file.Name(), ".readonly")] = true
+		}
+		if strings.HasSuffix(file.Name(), ".expires") {
+			temporary[strings.TrimSuffix(file.Name(), ".expires")] = true
+		}
+		if file.IsDir() {
+			continue
+		}
+		name, ok := s.trimSaveExt(file.Name())
+		if !ok {
+			continue
+		}
+		fi, err := file.Info()
+		if err != nil {
+			// Removed since the directory was read.
+			continue
+		}
+		info := SaveInfo{Name: name, ModTime: fi.ModTime(), Size: fi.Size(), IsQuickSave: name == quickSaveSlot}
+		rank := slices.Index(s.fileExts, s.fileSaveExt(file.Name()))
+		if i, ok := seen[name]; ok {
+			// Several variants exist; report the one LoadGame reads.
+			if rank < ranks[name] {
+				infos[i] = info
+				ranks[name] = rank
+			}
+			continue
+		}
+		ranks[name] = rank
+		seen[name] = len(infos)
+		infos = append(infos, info)
+	}
+	for i := range infos {
+		infos[i].HasThumbnail = thumbnails[infos[i].Name]
+		infos[i].ReadOnly = readOnly[infos[i].Name]
+		if temporary[infos[i].Name] {
+			infos[i].ExpiresAt, infos[i].Temporary, _ = s.readExpiry(infos[i].Name)
+		}
+		infos[i].Tags = []string{}
+		if tagged[infos[i].Name] {
+			if tags, err := s.readTags(infos[i].Name); err == nil {
+				infos[i].Tags = tags
+			}
+		}
+	}
+	sortSaveInfos(infos, SortByModTime, true)
+	return infos, nil
+}
+
+// SortBy selects the field GetSortedSaveInfos orders saves by.
+type SortBy string
+
+const (
+	SortByName    SortBy = "name"
+	SortByModTime SortBy = "modTime"
+	SortBySize    SortBy = "size"
+)
+
+// GetSortedSaveInfos is GetAllSaveInfos ordered by the given field, in
+// descending order if descending is set. Saves that tie are ordered by name,
+// so the order is the same on every call.
+func (s *SaveManager) GetSortedSaveInfos(by SortBy, descending bool) ([]SaveInfo, error) {
+	switch by {
+	case SortByName, SortByModTime, SortBySize:
+	default:
+		return nil, fmt.Errorf("unknown save sort order %q", by)
+	}
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		return nil, err
+	}
+	sortSaveInfos(infos, by, descending)
+	return infos, nil
+}
+
+// GetSaveInfosPage returns limit saves starting at offset, from the saves
+// ordered by the given field, along with the total number of saves for
+// pagers. Names sort A to Z, and modification times and sizes newest and
+// largest first. An offset past the end returns no saves.
+func (s *SaveManager) GetSaveInfosPage(offset, limit int, sort SortBy) ([]SaveInfo, int, error) {
+	if offset < 0 || limit < 0 {
+		return nil, 0, fmt.Errorf("invalid save page: offset %d, limit %d", offset, limit)
+	}
+	infos, err := s.GetSortedSaveInfos(sort, sort != SortByName)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(infos)
+	start := min(offset, total)
+	end := start + min(limit, total-start)
+	return infos[start:end], total, nil
+}
+
+func sortSaveInfos(infos []SaveInfo, by SortBy, descending bool) {
+	slices.SortFunc(infos, func(a, b SaveInfo) int {
+		var c int
+		switch by {
+		case SortByName:
+			c = strings.Compare(a.Name, b.Name)
+		case SortByModTime:
+			c = a.ModTime.Compare(b.ModTime)
+		case SortBySize:
+			c = cmp.Compare(a.Size, b.Size)
+		}
+		if descending {
+			c = -c
+		}
+		if c == 0 {
+			c = strings.Compare(a.Name, b.Name)
+		}
+		return c
+	})
+}
+
+// StatSave returns the details GetAllSaveInfos lists for saveName alone,
+// without reading the save itself.
+func (s *SaveManager) StatSave(saveName string) (SaveInfo, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return SaveInfo{}, err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	filename, err := s.savePath(saveName)
+	if err != nil {
+		return SaveInfo{}, fmt.Errorf("stat save %q: %w", saveName, err)
+	}
+	fi, err := s.fs.Stat(filename)
+	if err != nil {
+		return SaveInfo{}, fmt.Errorf("stat save %q: %w", saveName, err)
+	}
+	info := SaveInfo{Name: saveName, ModTime: fi.ModTime(), Size: fi.Size(), IsQuickSave: saveName == quickSaveSlot}
+	if _, err := s.fs.Stat(s.thumbnailPath(saveName)); err == nil {
+		info.HasThumbnail = true
+	}
+	if info.Tags, err = s.readTags(saveName); err != nil {
+		info.Tags = []string{}
+	}
+	info.ReadOnly, _ = s.isReadOnly(saveName)
+	info.ExpiresAt, info.Temporary, _ = s.readExpiry(saveName)
+	return info, nil
+}
+
+// TouchSave sets the modification time of saveName to now without rewriting
+// it, moving it to the front of GetAllSaveInfos.
+func (s *SaveManager) TouchSave(saveName string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	filename, err := s.savePath(saveName)
+	if err != nil {
+		return fmt.Errorf("touch save %q: %w", saveName, err)
+	}
+	now := s.now()
+	if err := s.fs.Chtimes(filename, now, now); err != nil {
+		return fmt.Errorf("touch save %q: %w", saveName, err)
+	}
+	return nil
+}
+
 func (s *SaveManager) SetLastSave(saveName string) error {
-	filename := filepath.Join(s.dataDir, ".last_save")
-	return ioutil.WriteFile(filename, []byte(saveName), 0644)
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	return s.store.SetLast(saveName)
 }
 
+// GetLastSave returns the save named by the last-save marker, or "" if no
+// marker is set or the save it names no longer exists.
 func (s *SaveManager) GetLastSave() (string, error) {
-	filename := filepath.Join(s.dataDir, ".last_save")
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return "", nil
+	return s.store.GetLast()
+}
+
+// replaceLastSave points the last-save marker at newName if it currently
+// names oldName, or clears it if newName is empty.
+func (s *SaveManager) replaceLastSave(oldName, newName string) error {
+	mu := s.saveLock(lastSaveFile)
+	mu.Lock()
+	defer mu.Unlock()
+	marker := filepath.Join(s.dir(), lastSaveFile)
+	last, err := readFile(s.fs, marker)
+	if err != nil || strings.TrimSpace(string(last)) != oldName {
+		return nil
 	}
-	return string(data), nil
+	if newName == "" {
+		return s.fs.Remove(marker)
+	}
+	return s.writeFileAtomic(marker, s.fileMode, func(w io.Writer) error {
+		_, err := io.WriteString(w, newName)
+		return err
+	})
 }
 
+// DeleteSave removes saveName together with its sidecars, its backups and
+// its autosave slot, and clears the last-save marker if it named the save.
+// Sidecars that are already gone are not an error.
 func (s *SaveManager) DeleteSave(saveName string) error {
-	filename := filepath.Join(s.dataDir, saveName+".json")
-	return os.Remove(filename)
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	if err := s.store.Delete(saveName); err != nil {
+		return fmt.Errorf("delete save %q: %w", saveName, err)
+	}
+	return nil
+}
+
+// DeleteSaves deletes each of saveNames like DeleteSave, carrying on past
+// failures. It returns the saves that were deleted and an error joining the
+// failures, if any.
+func (s *SaveManager) DeleteSaves(saveNames []string) (deleted []string, err error) {
+	deleted = []string{}
+	var errs []error
+	for _, saveName := range saveNames {
+		if err := s.DeleteSave(saveName); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted = append(deleted, saveName)
+	}
+	return deleted, errors.Join(errs...)
+}
+
+func (s *SaveManager) deleteSave(saveName string) error {
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	filename, err := s.savePath(saveName)
+	if err != nil {
+		return err
+	}
+	if err := s.checkNotReadOnly(saveName); err != nil {
+		return err
+	}
+	if err := s.fs.Remove(filename); err != nil {
+		return err
+	}
+	if err := s.removeSaveFiles(saveName); err != nil {
+		return err
+	}
+	if err := s.replaceLastSave(saveName, ""); err != nil {
+		return err
+	}
+	if isAutosave(saveName) {
+		return nil
+	}
+	names, err := s.saveNames()
+	if err != nil {
+		return err
+	}
+	autosaves := []string{saveName + autosaveSuffix}
+	for _, name := range names {
+		if _, ok := autosaveIndex(saveName, name); ok {
+			autosaves = append(autosaves, name)
+		}
+	}
+	for _, autosave := range autosaves {
+		if err := s.removeAutosave(autosave); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SaveManager) removeAutosave(autosave string) error {
+	mu := s.saveLock(autosave)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := s.removeSaveFiles(autosave); err != nil {
+		return err
+	}
+	return s.replaceLastSave(autosave, "")
+}
+
+// removeSaveFiles removes every file belonging to saveName, skipping any that
+// do not exist.
+func (s *SaveManager) removeSaveFiles(saveName string) error {
+	s.uncacheSave(saveName)
+	for _, ext := range append(slices.Clone(s.fileExts), sidecarExts...) {
+		if err := s.fs.Remove(filepath.Join(s.dir(), saveName+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := s.fs.RemoveAll(s.deltaDir(saveName)); err != nil {
+		return err
+	}
+	if err := s.fs.RemoveAll(s.bundleDir(saveName)); err != nil {
+		return err
+	}
+	return s.fs.RemoveAll(s.backupDir(saveName))
+}
+
+// windowsReservedNames are device names Windows refuses as file names,
+// whatever the extension.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// validateSaveName rejects names that are empty, contain path separators or
+// "..", start with a dot (reserved for internal files such as .last_save), or
+// are not valid file names on Windows.
+func validateSaveName(saveName string) error {
+	invalid := saveName == "" ||
+		strings.HasPrefix(saveName, ".") ||
+		strings.Contains(saveName, "..") ||
+		strings.ContainsAny(saveName, `/\<>:"|?*`) ||
+		strings.HasSuffix(saveName, " ") ||
+		strings.HasSuffix(saveName, ".") ||
+		windowsReservedNames[strings.ToUpper(strings.SplitN(saveName, ".", 2)[0])]
+	for _, r := range saveName {
+		if r < 0x20 || r == 0x7f {
+			invalid = true
+		}
+	}
+	if invalid {
+		return fmt.Errorf("%w: %q", ErrInvalidSaveName, saveName)
+	}
+	return nil
+}
+
+// lockSaves takes the write locks of both saves in a fixed order, so two
+// calls locking the same pair cannot deadlock, and returns the unlock func.
+func (s *SaveManager) lockSaves(a, b string) func() {
+	if b < a {
+		a, b = b, a
+	}
+	first, second := s.saveLock(a), s.saveLock(b)
+	first.Lock()
+	second.Lock()
+	return func() {
+		second.Unlock()
+		first.Unlock()
+	}
+}
+
+// RenameSave renames oldName to newName along with its sidecars and backups,
+// without rewriting the save. It returns ErrSaveExists if newName is taken,
+// and moves the last-save marker along if it pointed at oldName.
+func (s *SaveManager) RenameSave(oldName, newName string) error {
+	if err := validateSaveName(oldName); err != nil {
+		return err
+	}
+	if err := validateSaveName(newName); err != nil {
+		return err
+	}
+	if oldName == newName {
+		return nil
+	}
+	unlock := s.lockSaves(oldName, newName)
+	defer unlock()
+	s.uncacheSave(oldName)
+	s.uncacheSave(newName)
+	filename, err := s.savePath(oldName)
+	if err != nil {
+		return fmt.Errorf("rename save %q: %w", oldName, err)
+	}
+	if err := s.checkNotReadOnly(oldName); err != nil {
+		return fmt.Errorf("rename save %q: %w", oldName, err)
+	}
+	if _, err := s.savePath(newName); err == nil {
+		return fmt.Errorf("rename save %q to %q: %w", oldName, newName, ErrSaveExists)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := s.fs.Rename(filename, filepath.Join(s.dir(), newName+s.fileSaveExt(filename))); err != nil {
+		return err
+	}
+	for _, ext := range sidecarExts {
+		err := s.fs.Rename(filepath.Join(s.dir(), oldName+ext), filepath.Join(s.dir(), newName+ext))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	for _, dir := range []func(string) string{s.backupDir, s.deltaDir, s.bundleDir} {
+		if _, err := s.fs.Stat(dir(oldName)); err != nil {
+			continue
+		}
+		// Anything already under newName was left behind by a deleted save.
+		if err := s.fs.RemoveAll(dir(newName)); err != nil {
+			return err
+		}
+		if err := s.fs.Rename(dir(oldName), dir(newName)); err != nil {
+			return err
+		}
+	}
+
+	return s.replaceLastSave(oldName, newName)
+}
+
+// DuplicateSave copies srcName and its sidecars to the new slot dstName,
+// returning ErrSaveExists if dstName is taken. Backups are not copied.
+func (s *SaveManager) DuplicateSave(srcName, dstName string) error {
+	if err := validateSaveName(srcName); err != nil {
+		return err
+	}
+	if err := validateSaveName(dstName); err != nil {
+		return err
+	}
+	if srcName == dstName {
+		return fmt.Errorf("duplicate save %q: %w", srcName, ErrSaveExists)
+	}
+	unlock := s.lockSaves(srcName, dstName)
+	defer unlock()
+	s.uncacheSave(dstName)
+	filename, err := s.savePath(srcName)
+	if err != nil {
+		return fmt.Errorf("duplicate save %q: %w", srcName, err)
+	}
+	if _, err := s.savePath(dstName); err == nil {
+		return fmt.Errorf("duplicate save %q to %q: %w", srcName, dstName, ErrSaveExists)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if s.maxSaves > 0 && countsTowardLimit(dstName) {
+		s.createMu.Lock()
+		defer s.createMu.Unlock()
+		if err := s.checkSaveLimit(dstName); err != nil {
+			return err
+		}
+	}
+
+	for _, ext := range sidecarExts {
+		if ext == ".readonly" {
+			// The copy is there to be played.
+			continue
+		}
+		err := s.copyFileAtomic(filepath.Join(s.dir(), srcName+ext), filepath.Join(s.dir(), dstName+ext), s.fileMode)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := s.copySaveDir(s.deltaDir, srcName, dstName); err != nil {
+		return err
+	}
+	if err := s.copySaveDir(s.bundleDir, srcName, dstName); err != nil {
+		return err
+	}
+	// Copy the save itself last, so dstName only appears once it is complete.
+	return s.copyFileAtomic(filename, filepath.Join(s.dir(), dstName+s.fileSaveExt(filename)), s.fileMode)
+}
+
+// writeFileAtomic writes to filename+".tmp" and renames it over filename once
+// write has succeeded, so a crash mid-write never truncates the existing file.
+func (s *SaveManager) writeFileAtomic(filename string, perm os.FileMode, write func(w io.Writer) error) error {
+	tmp, err := s.writeTemp(filename, perm, write)
+	if err != nil {
+		return err
+	}
+	if err := s.fs.Rename(tmp, filename); err != nil {
+		s.fs.Remove(tmp)
+		return err
+	}
+	if s.durable {
+		return s.syncDir(filepath.Dir(filename))
+	}
+	return nil
+}
+
+// writeTemp writes the temporary file that will replace filename and returns
+// its name, recreating the directory if it has gone. Nothing is left behind
+// if it fails.
+func (s *SaveManager) writeTemp(filename string, perm os.FileMode, write func(w io.Writer) error) (string, error) {
+	if err := s.fs.MkdirAll(filepath.Dir(filename), s.dirMode); err != nil {
+		return "", err
+	}
+	tmp := filename + tmpExt
+	f, err := s.fs.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return "", err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		s.fs.Remove(tmp)
+		return "", err
+	}
+	if s.durable {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			s.fs.Remove(tmp)
+			return "", err
+		}
+	}
+	if err := f.Close(); err != nil {
+		s.fs.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// syncDir fsyncs dir so that renames into it are durable. Windows cannot
+// sync directories and makes renames durable on its own.
+func (s *SaveManager) syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := s.fs.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// ioChunkSize is how much SaveGameContext and LoadGameContext transfer between
+// checks for cancellation.
+const ioChunkSize = 64 << 10
+
+// copyContext copies r to w in chunks, stopping early if ctx is done.
+func copyContext(ctx context.Context, w io.Writer, r io.Reader) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := io.CopyN(w, r, ioChunkSize)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readAllContext reads r to the end in chunks, stopping early if ctx is done.
+// sizeHint, if known, avoids regrowing the buffer.
+func readAllContext(ctx context.Context, r io.Reader, sizeHint int64) ([]byte, error) {
+	var buf bytes.Buffer
+	if sizeHint > 0 {
+		buf.Grow(int(sizeHint))
+	}
+	if err := copyContext(ctx, &buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *SaveManager) copyFileAtomic(src, dst string, perm os.FileMode) error {
+	in, err := s.fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return s.writeFileAtomic(dst, perm, func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
 }
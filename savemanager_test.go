@@ -0,0 +1,951 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestManager returns a SaveManager keeping its saves in a fresh
+// temporary directory.
+func newTestManager(t *testing.T, opts ...Option) (*SaveManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := NewSaveManagerWithDir(dir, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, dir
+}
+
+// mustSave saves saveData as saveName, failing the test if it can't.
+func mustSave(t *testing.T, s *SaveManager, saveName, saveData string) {
+	t.Helper()
+	if err := s.SaveGame(saveName, saveData); err != nil {
+		t.Fatalf("SaveGame(%q): %v", saveName, err)
+	}
+}
+
+// mustLoad loads saveName, failing the test if it can't.
+func mustLoad(t *testing.T, s *SaveManager, saveName string) string {
+	t.Helper()
+	data, err := s.LoadGame(saveName)
+	if err != nil {
+		t.Fatalf("LoadGame(%q): %v", saveName, err)
+	}
+	return data
+}
+
+// setModTime sets the modification time of the save file of saveName in dir.
+func setModTime(t *testing.T, dir, saveName string, mtime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(filepath.Join(dir, saveName+defaultSaveExt), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveGameFailedWriteKeepsPreviousSave(t *testing.T) {
+	mem := newMemFS()
+	s, err := NewSaveManagerWithDir("/data", WithFileSystem(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+
+	mem.writeErr = errors.New("disk failed")
+	if err := s.SaveGame("farm", `{"day":2}`); err == nil {
+		t.Fatal("SaveGame succeeded with a failing writer")
+	}
+	mem.writeErr = nil
+
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s, want the previous save", got)
+	}
+	for name := range mem.files {
+		if strings.HasSuffix(name, tmpExt) {
+			t.Errorf("temporary file %s left behind", name)
+		}
+	}
+}
+
+func TestWriteFileAtomicFailedWriteLeavesFile(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+
+	filename := filepath.Join(dir, "farm"+defaultSaveExt)
+	err := s.writeFileAtomic(filename, s.fileMode, func(w io.Writer) error {
+		io.WriteString(w, `{"da`)
+		return errors.New("interrupted")
+	})
+	if err == nil {
+		t.Fatal("writeFileAtomic succeeded with a failing writer")
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s, want the previous save", got)
+	}
+	if _, err := os.Stat(filename + tmpExt); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestConcurrentSaveAndLoad(t *testing.T) {
+	s, _ := newTestManager(t)
+	const writers, rounds = 8, 50
+	saveOf := func(writer int) string {
+		return fmt.Sprintf(`{"writer":%d,"pad":"%s"}`, writer, strings.Repeat("x", 4096))
+	}
+	valid := make(map[string]bool)
+	for w := 0; w < writers; w++ {
+		valid[saveOf(w)] = true
+	}
+	names := []string{"farm1", "farm2"}
+	for _, name := range names {
+		mustSave(t, s, name, saveOf(0))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := saveOf(w)
+			for i := 0; i < rounds; i++ {
+				name := names[i%2]
+				if err := s.SaveGame(name, data); err != nil {
+					t.Errorf("SaveGame(%q): %v", name, err)
+					return
+				}
+				got, err := s.LoadGame(name)
+				if err != nil {
+					t.Errorf("LoadGame(%q): %v", name, err)
+					return
+				}
+				if !valid[got] {
+					t.Errorf("LoadGame(%q) returned a corrupt save of %d bytes", name, len(got))
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCompressedSaveRoundTrip(t *testing.T) {
+	s, dir := newTestManager(t, WithCompression(true))
+	var b strings.Builder
+	b.WriteString(`{"tiles":[`)
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&b, `{"x":%d,"crop":"parsnip"},`, i)
+	}
+	b.WriteString(`{}]}`)
+	saveData := b.String()
+	mustSave(t, s, "farm", saveData)
+
+	raw, err := os.ReadFile(filepath.Join(dir, "farm.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) >= len(saveData) {
+		t.Errorf("compressed save is %d bytes, not smaller than %d", len(raw), len(saveData))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != saveData {
+		t.Error("file does not decompress to the original save")
+	}
+	if got := mustLoad(t, s, "farm"); got != saveData {
+		t.Error("LoadGame does not return the original save")
+	}
+}
+
+func TestCompressionLoadsUncompressedSaves(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := NewSaveManagerWithDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, plain, "old", `{"day":3}`)
+
+	s, err := NewSaveManagerWithDir(dir, WithCompression(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "old"); got != `{"day":3}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+	mustSave(t, s, "old", `{"day":4}`)
+	if _, err := os.Stat(filepath.Join(dir, "old.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("uncompressed variant not replaced: %v", err)
+	}
+	mustSave(t, s, "new", `{"day":1}`)
+	names, err := s.GetAllSaves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"new", "old"}) {
+		t.Errorf("GetAllSaves = %v, want [new old]", names)
+	}
+}
+
+func TestValidateSaveName(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		valid bool
+	}{
+		{"farm", true},
+		{"My Farm 2", true},
+		{"farm.v2", true},
+		{"存档一", true},
+		{"console", true},
+		{"", false},
+		{"../../etc/passwd", false},
+		{"..", false},
+		{"foo/bar", false},
+		{`foo\bar`, false},
+		{".hidden", false},
+		{"a:b", false},
+		{"a*b", false},
+		{"a?b", false},
+		{`a"b`, false},
+		{"a<b>", false},
+		{"a|b", false},
+		{"CON", false},
+		{"nul.txt", false},
+		{"farm.", false},
+		{"farm ", false},
+		{"farm\x00", false},
+	} {
+		err := validateSaveName(tc.name)
+		if tc.valid && err != nil {
+			t.Errorf("validateSaveName(%q) = %v, want nil", tc.name, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrInvalidSaveName) {
+			t.Errorf("validateSaveName(%q) = %v, want ErrInvalidSaveName", tc.name, err)
+		}
+	}
+}
+
+func TestInvalidSaveNameRejected(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	const bad = "../escaped"
+	if err := s.SaveGame(bad, `{}`); !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("SaveGame = %v", err)
+	}
+	if _, err := s.LoadGame(bad); !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("LoadGame = %v", err)
+	}
+	if err := s.DeleteSave(bad); !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("DeleteSave = %v", err)
+	}
+	if err := s.RenameSave("farm", bad); !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("RenameSave = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escaped.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("save written outside the data directory: %v", err)
+	}
+}
+
+// dirFiles returns the paths, relative to dir, of the files under dir.
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestDeleteSaveRemovesSidecarsAndBackups(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2}`)
+	mustSave(t, s, "farm"+autosaveSuffix, `{"day":2}`)
+	mustSave(t, s, "farm"+autosaveSuffix, `{"day":3}`)
+	mustSave(t, s, "other", `{"day":9}`)
+	if !slices.Contains(dirFiles(t, dir), "farm.sha256") {
+		t.Fatal("save written without a checksum sidecar")
+	}
+	if err := s.DeleteSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	left := dirFiles(t, dir)
+	if len(left) == 0 {
+		t.Fatal("DeleteSave removed the unrelated save")
+	}
+	for _, name := range left {
+		if !strings.HasPrefix(name, "other.") {
+			t.Errorf("%s left behind", name)
+		}
+	}
+	// Missing sidecars count as already removed.
+	mustSave(t, s, "bare", `{}`)
+	os.Remove(filepath.Join(dir, "bare.sha256"))
+	if err := s.DeleteSave("bare"); err != nil {
+		t.Errorf("DeleteSave without a checksum: %v", err)
+	}
+}
+
+func TestMissingSaveNotFound(t *testing.T) {
+	s, _ := newTestManager(t)
+	if _, err := s.LoadGame("missing"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadGame = %v, want ErrSaveNotFound", err)
+	}
+	err := s.DeleteSave("missing")
+	if !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("DeleteSave = %v, want ErrSaveNotFound", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("error %q does not name the save", err)
+	}
+}
+
+func TestDuplicateSave(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	if err := s.SetLastSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DuplicateSave("farm", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{defaultSaveExt, ".sha256"} {
+		src, err := os.ReadFile(filepath.Join(dir, "farm"+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst, err := os.ReadFile(filepath.Join(dir, "copy"+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(src, dst) {
+			t.Errorf("copy%s differs from farm%s", ext, ext)
+		}
+	}
+	if err := s.DuplicateSave("farm", "copy"); !errors.Is(err, ErrSaveExists) {
+		t.Errorf("DuplicateSave onto an existing save = %v, want ErrSaveExists", err)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "farm" {
+		t.Errorf("GetLastSave = %q, %v, want farm", last, err)
+	}
+
+	mustSave(t, s, "copy", `{"day":2}`)
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("saving the copy changed the original to %s", got)
+	}
+	mustSave(t, s, "farm", `{"day":3}`)
+	if got := mustLoad(t, s, "copy"); got != `{"day":2}` {
+		t.Errorf("saving the original changed the copy to %s", got)
+	}
+}
+
+func TestSaveLimit(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSaveManagerWithDir(dir, WithMaxSaves(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm1", `{}`)
+	mustSave(t, s, "farm2", `{}`)
+	// Autosaves and backups don't count.
+	mustSave(t, s, "farm2"+autosaveSuffix, `{}`)
+	mustSave(t, s, "farm2", `{"day":2}`)
+
+	if err := s.SaveGame("farm3", `{}`); !errors.Is(err, ErrSaveLimitReached) {
+		t.Fatalf("SaveGame beyond the limit = %v, want ErrSaveLimitReached", err)
+	}
+	if _, err := s.LoadGame("farm3"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("refused save was written: %v", err)
+	}
+	if err := s.SaveGame("farm1", `{"day":2}`); err != nil {
+		t.Errorf("overwriting at the limit: %v", err)
+	}
+
+	raised, err := NewSaveManagerWithDir(dir, WithMaxSaves(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := raised.SaveGame("farm3", `{}`); err != nil {
+		t.Errorf("SaveGame after raising the limit: %v", err)
+	}
+}
+
+func TestDeleteSaveClearsLastSave(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.SetLastSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "" {
+		t.Errorf("GetLastSave after delete = %q, %v, want \"\"", last, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, lastSaveFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("marker not cleared: %v", err)
+	}
+}
+
+func TestGetLastSaveDanglingMarker(t *testing.T) {
+	s, dir := newTestManager(t)
+	// Left by a version that didn't clear the marker on delete.
+	if err := os.WriteFile(filepath.Join(dir, lastSaveFile), []byte("deleted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if last, err := s.GetLastSave(); err != nil || last != "" {
+		t.Errorf("GetLastSave = %q, %v, want \"\"", last, err)
+	}
+}
+
+func TestGetLastSaveTrimsMarker(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	if err := os.WriteFile(filepath.Join(dir, lastSaveFile), []byte(" farm\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	last, err := s.GetLastSave()
+	if err != nil || last != "farm" {
+		t.Fatalf("GetLastSave = %q, %v, want farm", last, err)
+	}
+	if got := mustLoad(t, s, last); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+	if err := s.SetLastSave("../farm"); !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("SetLastSave = %v, want ErrInvalidSaveName", err)
+	}
+}
+
+func TestSaveGameCompactsByDefault(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", "{ \"crops\" : [1, 2] }")
+	data, err := os.ReadFile(filepath.Join(dir, "farm"+defaultSaveExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"crops":[1,2]}` {
+		t.Errorf("saved %q, want it compacted", data)
+	}
+}
+
+func TestSaveGamePrettyPrint(t *testing.T) {
+	s, _ := newTestManager(t, WithPrettyPrint(true))
+	mustSave(t, s, "farm", `{"crops":[1]}`)
+	want := "{\n  \"crops\": [\n    1\n  ]\n}"
+	if got := mustLoad(t, s, "farm"); got != want {
+		t.Errorf("LoadGame = %q, want %q", got, want)
+	}
+}
+
+func TestPrettyPrintRejectsNonJSON(t *testing.T) {
+	for _, pretty := range []bool{false, true} {
+		s, _ := newTestManager(t, WithPrettyPrint(pretty))
+		if err := s.SaveGame("farm", "not json"); !errors.Is(err, ErrInvalidSaveData) {
+			t.Errorf("pretty %v: SaveGame = %v, want ErrInvalidSaveData", pretty, err)
+		}
+	}
+}
+
+func TestSortedKeysStableBytes(t *testing.T) {
+	for _, pretty := range []bool{false, true} {
+		s, dir := newTestManager(t, WithSortedKeys(true), WithPrettyPrint(pretty))
+		// The same state, as two different map iterations would write it.
+		mustSave(t, s, "first", `{"money":1,"farm":{"crops":[1,{"kind":"corn","name":"<a>"}],"size":1.50}}`)
+		mustSave(t, s, "second", `{"farm":{"size":1.50,"crops":[1,{"name":"<a>","kind":"corn"}]},"money":1}`)
+		first, err := os.ReadFile(filepath.Join(dir, "first"+defaultSaveExt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := os.ReadFile(filepath.Join(dir, "second"+defaultSaveExt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, second) {
+			t.Errorf("pretty %v: saved\n%s\nand\n%s\nwant identical bytes", pretty, first, second)
+		}
+	}
+}
+
+func TestSaveGameValidatesJSON(t *testing.T) {
+	s, _ := newTestManager(t)
+	for _, saveData := range []string{"", `{"day":`} {
+		if err := s.SaveGame("farm", saveData); !errors.Is(err, ErrInvalidSaveData) {
+			t.Errorf("SaveGame(%q) = %v, want ErrInvalidSaveData", saveData, err)
+		}
+	}
+	if _, err := s.LoadGame("farm"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("invalid save was written: %v", err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+}
+
+func TestSaveGameRaw(t *testing.T) {
+	s, _ := newTestManager(t)
+	if err := s.SaveGameRaw("notes", "not json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, s, "notes"); got != "not json" {
+		t.Errorf("LoadGame = %q", got)
+	}
+}
+
+func TestDurableWritesSync(t *testing.T) {
+	mem := newMemFS()
+	s, err := NewSaveManagerWithDir("/data", WithFileSystem(mem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{}`)
+	if mem.syncs != 0 {
+		t.Errorf("%d syncs without durable writes", mem.syncs)
+	}
+
+	durable, err := NewSaveManagerWithDir("/data", WithFileSystem(mem), WithDurableWrites(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, durable, "farm", `{"day":2}`)
+	// At least the save file and then its directory.
+	if mem.syncs < 2 {
+		t.Errorf("%d syncs with durable writes, want the file and its directory", mem.syncs)
+	}
+	if got := mustLoad(t, durable, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+}
+
+func TestDurableWritesOnDisk(t *testing.T) {
+	s, _ := newTestManager(t, WithDurableWrites(true))
+	mustSave(t, s, "farm", `{"day":1}`)
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+}
+
+func TestTouchSaveMovesItToFront(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	mustSave(t, s, "mine", `{}`)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "farm"+defaultSaveExt), old, old); err != nil {
+		t.Fatal(err)
+	}
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos[0].Name != "mine" {
+		t.Fatalf("GetAllSaveInfos starts with %s, want mine", infos[0].Name)
+	}
+
+	if err := s.TouchSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	infos, err = s.GetAllSaveInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos[0].Name != "farm" {
+		t.Errorf("GetAllSaveInfos starts with %s after touching farm", infos[0].Name)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{}` {
+		t.Errorf("TouchSave changed the save to %s", got)
+	}
+	if err := s.TouchSave("missing"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("TouchSave = %v, want ErrSaveNotFound", err)
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix permission bits")
+	}
+	dir := filepath.Join(t.TempDir(), "saves")
+	s, err := NewSaveManagerWithDir(dir, WithFileMode(0600), WithDirMode(0700))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2}`)
+	if err := s.SetLastSave("farm"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"farm.json", "farm.sha256", "farm.header", lastSaveFile} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0600 {
+			t.Errorf("%s has mode %v, want 0600", name, fi.Mode().Perm())
+		}
+	}
+	for _, name := range []string{dir, filepath.Join(dir, "backups", "farm")} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0700 {
+			t.Errorf("%s has mode %v, want 0700", name, fi.Mode().Perm())
+		}
+	}
+}
+
+func TestLoadGameEmptyVersusMissing(t *testing.T) {
+	s, dir := newTestManager(t)
+	if err := os.WriteFile(filepath.Join(dir, "empty"+defaultSaveExt), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+
+	if _, err := s.LoadGame("empty"); !errors.Is(err, ErrEmptySave) {
+		t.Errorf("LoadGame(empty) = %v, want ErrEmptySave", err)
+	}
+	if _, err := s.LoadGame("missing"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadGame(missing) = %v, want ErrSaveNotFound", err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame(farm) = %s", got)
+	}
+	for saveName, want := range map[string]bool{"empty": true, "farm": true, "missing": false} {
+		if ok, err := s.HasSave(saveName); err != nil || ok != want {
+			t.Errorf("HasSave(%q) = %v, %v, want %v", saveName, ok, err, want)
+		}
+	}
+}
+
+func TestSaveExtSeparatesSaveTypes(t *testing.T) {
+	dir := t.TempDir()
+	games, err := NewSaveManagerWithDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := NewSaveManagerWithDir(dir, WithSaveExt(".config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, games, "farm", `{"day":1}`)
+	mustSave(t, configs, "controls", `{"jump":"space"}`)
+
+	if names, err := games.GetAllSaves(); err != nil || !slices.Equal(names, []string{"farm"}) {
+		t.Errorf("GetAllSaves(.json) = %v, %v, want [farm]", names, err)
+	}
+	if names, err := configs.GetAllSaves(); err != nil || !slices.Equal(names, []string{"controls"}) {
+		t.Errorf("GetAllSaves(.config) = %v, %v, want [controls]", names, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "controls.config")); err != nil {
+		t.Errorf("config not saved with its extension: %v", err)
+	}
+	if got := mustLoad(t, configs, "controls"); got != `{"jump":"space"}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+}
+
+func TestSaveGameWithResult(t *testing.T) {
+	s, dir := newTestManager(t)
+	saveData := `{"notes":"` + strings.Repeat("x", 1000) + `"}`
+	result, err := s.SaveGameWithResult("farm", saveData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Bytes != int64(len(saveData)) {
+		t.Errorf("Bytes = %d, want %d", result.Bytes, len(saveData))
+	}
+	if want := filepath.Join(dir, "farm"+defaultSaveExt); result.Path != want {
+		t.Errorf("Path = %s, want %s", result.Path, want)
+	}
+	if result.Compressed {
+		t.Error("Compressed set for an uncompressed save")
+	}
+
+	compressed, err := NewSaveManagerWithDir(dir, WithCompression(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = compressed.SaveGameWithResult("farm", saveData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Compressed || result.Bytes >= int64(len(saveData)) {
+		t.Errorf("compressed result = %+v", result)
+	}
+}
+
+func TestDeleteSaves(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	mustSave(t, s, "mine", `{}`)
+	mustSave(t, s, "keep", `{}`)
+	if err := s.SetLastSave("mine"); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := s.DeleteSaves([]string{"farm", "missing", "mine", "../escaped"})
+	if !slices.Equal(deleted, []string{"farm", "mine"}) {
+		t.Errorf("deleted %v, want [farm mine]", deleted)
+	}
+	if !errors.Is(err, ErrSaveNotFound) || !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("DeleteSaves error %v does not report both failures", err)
+	}
+	if names, err := s.GetAllSaves(); err != nil || !slices.Equal(names, []string{"keep"}) {
+		t.Errorf("GetAllSaves = %v, %v, want [keep]", names, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, lastSaveFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("marker for a deleted save not cleared: %v", err)
+	}
+}
+
+func TestLoadGameStripsBOM(t *testing.T) {
+	s, dir := newTestManager(t)
+	if err := os.WriteFile(filepath.Join(dir, "farm.json"), []byte("\xef\xbb\xbf{\"day\":1}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := mustLoad(t, s, "farm")
+	if got != `{"day":1}` || !json.Valid([]byte(got)) {
+		t.Errorf("LoadGame = %q, want the BOM stripped", got)
+	}
+}
+
+func TestLoadGameNormalizesLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "farm.json"), []byte("{\r\n  \"day\": 1\r\n}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSaveManagerWithDir(dir, WithNormalizeLineEndings(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mustLoad(t, s, "farm")
+	if got != "{\n  \"day\": 1\n}" || !json.Valid([]byte(got)) {
+		t.Errorf("LoadGame = %q, want LF line endings", got)
+	}
+
+	raw, err := NewSaveManagerWithDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, raw, "farm"); !strings.Contains(got, "\r\n") {
+		t.Errorf("LoadGame without the option = %q, want CRLF kept", got)
+	}
+}
+
+func TestSaveGameRecreatesVanishedDataDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "saves")
+	s, err := NewSaveManagerWithDir(dir, WithFileLocks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{"day":1}`)
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame = %s", got)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadGame("farm"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("LoadGame after the directory vanished = %v, want ErrSaveNotFound", err)
+	}
+	if err := s.SetLastSave("farm"); err != nil {
+		t.Errorf("SetLastSave after the directory vanished: %v", err)
+	}
+}
+
+func TestSaveInfosStableOrder(t *testing.T) {
+	s, dir := newTestManager(t)
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, saveName := range []string{"barn", "acre", "cove"} {
+		mustSave(t, s, saveName, `"`+saveName+strings.Repeat("x", int(saveName[0]-'a'))+`"`)
+		setModTime(t, dir, saveName, at)
+	}
+	setModTime(t, dir, "cove", at.Add(-time.Hour))
+	names := func(infos []SaveInfo, err error) []string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+		return names
+	}
+
+	// acre and barn share a modification time, so name breaks the tie.
+	for i := 0; i < 5; i++ {
+		if got := names(s.GetAllSaveInfos()); !slices.Equal(got, []string{"acre", "barn", "cove"}) {
+			t.Fatalf("GetAllSaveInfos = %v", got)
+		}
+	}
+	for _, tc := range []struct {
+		by         SortBy
+		descending bool
+		want       []string
+	}{
+		{SortByName, false, []string{"acre", "barn", "cove"}},
+		{SortByName, true, []string{"cove", "barn", "acre"}},
+		{SortByModTime, false, []string{"cove", "acre", "barn"}},
+		{SortByModTime, true, []string{"acre", "barn", "cove"}},
+		{SortBySize, false, []string{"acre", "barn", "cove"}},
+		{SortBySize, true, []string{"cove", "barn", "acre"}},
+	} {
+		if got := names(s.GetSortedSaveInfos(tc.by, tc.descending)); !slices.Equal(got, tc.want) {
+			t.Errorf("GetSortedSaveInfos(%s, %v) = %v, want %v", tc.by, tc.descending, got, tc.want)
+		}
+	}
+	if _, err := s.GetSortedSaveInfos("color", false); err == nil {
+		t.Error("GetSortedSaveInfos accepted an unknown sort")
+	}
+}
+
+func TestStatSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	if err := s.SaveGameWithThumbnail("farm", `{"day":1}`, []byte("png data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.TagSave("farm", []string{"main"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := s.StatSave("farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info, infos[0]) {
+		t.Errorf("StatSave = %+v, want %+v as listed", info, infos[0])
+	}
+	if info.Size != int64(len(`{"day":1}`)) || !info.HasThumbnail {
+		t.Errorf("StatSave = %+v", info)
+	}
+	if _, err := s.StatSave("missing"); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("StatSave(missing) = %v, want ErrSaveNotFound", err)
+	}
+}
+
+func TestGetSaveInfosPage(t *testing.T) {
+	s, _ := newTestManager(t)
+	for i := 0; i < 7; i++ {
+		mustSave(t, s, fmt.Sprint("farm", i), `{}`)
+	}
+	for _, tc := range []struct {
+		name          string
+		offset, limit int
+		want          []string
+	}{
+		{"first page", 0, 3, []string{"farm0", "farm1", "farm2"}},
+		{"last partial page", 6, 3, []string{"farm6"}},
+		{"past the end", 10, 3, nil},
+	} {
+		infos, total, err := s.GetSaveInfosPage(tc.offset, tc.limit, SortByName)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if total != 7 {
+			t.Errorf("%s: total = %d, want 7", tc.name, total)
+		}
+		if infos == nil {
+			t.Errorf("%s: nil page", tc.name)
+		}
+		var got []string
+		for _, info := range infos {
+			got = append(got, info.Name)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: page = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if _, _, err := s.GetSaveInfosPage(0, 3, "color"); err == nil {
+		t.Error("GetSaveInfosPage accepted an unknown sort")
+	}
+}
+
+func TestGetAllSavesStrictUnreadableDir(t *testing.T) {
+	bfs := &badDirFS{memFS: newMemFS()}
+	s, err := NewSaveManagerWithDir("/data", WithFileSystem(bfs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{}`)
+	bfs.dirErr = os.ErrPermission
+	if names, err := s.GetAllSavesStrict(); !errors.Is(err, os.ErrPermission) || names != nil {
+		t.Errorf("GetAllSavesStrict = %v, %v, want ErrPermission", names, err)
+	}
+	if names, err := s.GetAllSaves(); err != nil || len(names) != 0 {
+		t.Errorf("GetAllSaves = %v, %v, want no saves and no error", names, err)
+	}
+	bfs.dirErr = os.ErrNotExist
+	if names, err := s.GetAllSavesStrict(); err != nil || len(names) != 0 {
+		t.Errorf("GetAllSavesStrict of a missing dir = %v, %v, want no saves", names, err)
+	}
+}
+
+func TestGetAllSavesStrictUnstattableEntry(t *testing.T) {
+	bfs := &badDirFS{memFS: newMemFS()}
+	s, err := NewSaveManagerWithDir("/data", WithFileSystem(bfs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{}`)
+	mustSave(t, s, "ranch", `{}`)
+	bfs.badName = "ranch" + defaultSaveExt
+	names, err := s.GetAllSavesStrict()
+	var unreadable *UnreadableSavesError
+	if !errors.As(err, &unreadable) {
+		t.Fatalf("GetAllSavesStrict error = %v, want *UnreadableSavesError", err)
+	}
+	if !slices.Equal(unreadable.Files, []string{"ranch" + defaultSaveExt}) {
+		t.Errorf("unreadable files = %v, want [ranch%s]", unreadable.Files, defaultSaveExt)
+	}
+	if !slices.Equal(names, []string{"farm"}) {
+		t.Errorf("GetAllSavesStrict = %v, want [farm]", names)
+	}
+}
+
+func TestSaveGameNewName(t *testing.T) {
+	s, _ := newTestManager(t, WithMaxSaves(3))
+	for _, want := range []string{"Farm", "Farm (2)", "Farm (3)"} {
+		if got, err := s.SaveGameNewName("Farm", `{}`); err != nil || got != want {
+			t.Errorf("SaveGameNewName(%q) = %q, %v, want %q", "Farm", got, err, want)
+		}
+	}
+	if got, err := s.SaveGameNewName("Farm", `{}`); !errors.Is(err, ErrSaveLimitReached) || got != "" {
+		t.Errorf("SaveGameNewName past the limit = %q, %v, want ErrSaveLimitReached", got, err)
+	}
+	if _, err := s.SaveGameNewName("../Farm", `{}`); !errors.Is(err, ErrInvalidSaveName) {
+		t.Errorf("SaveGameNewName of an invalid name = %v, want ErrInvalidSaveName", err)
+	}
+}
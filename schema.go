@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSaveSchemaInvalid is returned by LoadGame when a save is valid JSON but
+// lacks a field set with WithRequiredFields or holds one of the wrong type.
+var ErrSaveSchemaInvalid = errors.New("save does not match schema")
+
+// WithRequiredFields makes LoadGame check that every save it returns has the
+// given fields, failing with ErrSaveSchemaInvalid if not, so that a damaged
+// save is caught at load rather than deep in gameplay code. Each field is a
+// dotted path as taken by LoadField, optionally followed by a colon and the
+// JSON type the value must have: object, array, string, number, boolean or
+// null. For example, "player.name:string" or "inventory:array".
+func WithRequiredFields(fields []string) Option {
+	return func(s *SaveManager) {
+		s.requiredFields = fields
+	}
+}
+
+// checkSchema reports the fields of saveData that are missing or of the wrong
+// type, as required by WithRequiredFields.
+func (s *SaveManager) checkSchema(saveData string) error {
+	if len(s.requiredFields) == 0 {
+		return nil
+	}
+	state, err := decodeState(saveData)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, field := range s.requiredFields {
+		path, want, _ := strings.Cut(field, ":")
+		value, ok := lookupPath(state, path)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("missing %q", path))
+		case want != "" && jsonType(value) != want:
+			problems = append(problems, fmt.Sprintf("%q is %s, want %s", path, jsonType(value), want))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrSaveSchemaInvalid, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// lookupPath returns the value at the dotted path in state, as decoded by
+// decodeState.
+func lookupPath(state any, path string) (any, bool) {
+	if path == "" {
+		return state, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := state.(type) {
+		case map[string]any:
+			value, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			state = value
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			state = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return state, true
+}
+
+// jsonType returns the name of the JSON type of value.
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
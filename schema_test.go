@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newSchemaTestManager(t *testing.T) *SaveManager {
+	t.Helper()
+	s, _ := newTestManager(t, WithRequiredFields([]string{"player.name:string", "gold:number", "party.0", "flags"}))
+	return s
+}
+
+func TestRequiredFieldsValidSave(t *testing.T) {
+	s := newSchemaTestManager(t)
+	mustSave(t, s, "farm", `{"player":{"name":"Ann"},"gold":3,"party":[{}],"flags":null}`)
+	mustLoad(t, s, "farm")
+}
+
+func TestRequiredFieldsMissingField(t *testing.T) {
+	s := newSchemaTestManager(t)
+	mustSave(t, s, "farm", `{"player":{"name":"Ann"},"party":[]}`)
+	_, err := s.LoadGame("farm")
+	if !errors.Is(err, ErrSaveSchemaInvalid) {
+		t.Fatalf("LoadGame = %v, want ErrSaveSchemaInvalid", err)
+	}
+	for _, field := range []string{"gold", "party.0", "flags"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error %q does not name missing field %s", err, field)
+		}
+	}
+}
+
+func TestRequiredFieldsWrongType(t *testing.T) {
+	s := newSchemaTestManager(t)
+	mustSave(t, s, "farm", `{"player":{"name":1},"gold":"lots","party":[{}],"flags":{}}`)
+	_, err := s.LoadGame("farm")
+	if !errors.Is(err, ErrSaveSchemaInvalid) {
+		t.Fatalf("LoadGame = %v, want ErrSaveSchemaInvalid", err)
+	}
+	for _, field := range []string{"player.name", "gold"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error %q does not name mistyped field %s", err, field)
+		}
+	}
+}
+
+func TestRequiredFieldsRawLoad(t *testing.T) {
+	s := newSchemaTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	rc, err := s.LoadGameReader("farm")
+	if err != nil {
+		t.Fatalf("LoadGameReader checked the schema: %v", err)
+	}
+	rc.Close()
+}
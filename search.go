@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// FindSaves returns the saves whose names contain query, ignoring case, most
+// recently modified first. A query with glob characters such as "winter*"
+// must match the whole name instead. An empty query matches every save.
+func (s *SaveManager) FindSaves(query string) ([]SaveInfo, error) {
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(query)
+	glob := strings.ContainsAny(query, "*?[")
+	if glob {
+		if _, err := path.Match(query, ""); err != nil {
+			return nil, fmt.Errorf("find saves %q: %w", query, err)
+		}
+	}
+	found := []SaveInfo{}
+	for _, info := range infos {
+		name := strings.ToLower(info.Name)
+		var ok bool
+		if glob {
+			ok, _ = path.Match(query, name)
+		} else {
+			ok = strings.Contains(name, query)
+		}
+		if ok {
+			found = append(found, info)
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFindSaves(t *testing.T) {
+	s, dir := newTestManager(t)
+	now := time.Now()
+	for i, saveName := range []string{"WinterFarm", "summer", "winter2"} {
+		mustSave(t, s, saveName, `{}`)
+		setModTime(t, dir, saveName, now.Add(time.Duration(i-3)*time.Hour))
+	}
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"winter*", []string{"winter2", "WinterFarm"}},
+		{"UMM", []string{"summer"}},
+		{"farm", []string{"WinterFarm"}},
+		{"autumn", nil},
+		{"", []string{"winter2", "summer", "WinterFarm"}},
+	} {
+		infos, err := s.FindSaves(tc.query)
+		if err != nil {
+			t.Errorf("FindSaves(%q): %v", tc.query, err)
+			continue
+		}
+		var got []string
+		for _, info := range infos {
+			got = append(got, info.Name)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("FindSaves(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+	if _, err := s.FindSaves("winter["); err == nil {
+		t.Error("FindSaves accepted a malformed pattern")
+	}
+}
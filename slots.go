@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultSlotCount = 3
+
+	// slotPrefix names the saves behind numbered slots: slot 1 is "slot_1".
+	slotPrefix = "slot_"
+)
+
+// ErrInvalidSlot is returned for slot indices outside 1 to the slot count.
+var ErrInvalidSlot = errors.New("invalid save slot")
+
+// SlotInfo describes a numbered save slot.
+type SlotInfo struct {
+	Index    int       `json:"index"`
+	Occupied bool      `json:"occupied"`
+	ModTime  time.Time `json:"modTime"`
+	Size     int64     `json:"size"`
+}
+
+// WithSlotCount sets how many numbered slots SaveSlot, LoadSlot and GetSlots
+// offer.
+func WithSlotCount(n int) Option {
+	return func(s *SaveManager) {
+		s.slotCount = n
+	}
+}
+
+// slotName returns the save behind slot index.
+func (s *SaveManager) slotName(index int) (string, error) {
+	if index < 1 || index > s.slotCount {
+		return "", fmt.Errorf("%w: %d is not between 1 and %d", ErrInvalidSlot, index, s.slotCount)
+	}
+	return fmt.Sprintf("%s%d", slotPrefix, index), nil
+}
+
+// SaveSlot saves saveData to numbered slot index, counting from 1.
+func (s *SaveManager) SaveSlot(index int, saveData string) error {
+	saveName, err := s.slotName(index)
+	if err != nil {
+		return err
+	}
+	return s.SaveGame(saveName, saveData)
+}
+
+// LoadSlot loads numbered slot index, counting from 1.
+func (s *SaveManager) LoadSlot(index int) (string, error) {
+	saveName, err := s.slotName(index)
+	if err != nil {
+		return "", err
+	}
+	return s.LoadGame(saveName)
+}
+
+// GetSlots reports every numbered slot in order, occupied or not.
+func (s *SaveManager) GetSlots() ([]SlotInfo, error) {
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]SaveInfo, len(infos))
+	for _, info := range infos {
+		byName[info.Name] = info
+	}
+	slots := make([]SlotInfo, s.slotCount)
+	for i := range slots {
+		slots[i].Index = i + 1
+		saveName, _ := s.slotName(i + 1)
+		if info, ok := byName[saveName]; ok {
+			slots[i].Occupied = true
+			slots[i].ModTime = info.ModTime
+			slots[i].Size = info.Size
+		}
+	}
+	return slots, nil
+}
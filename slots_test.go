@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetSlots(t *testing.T) {
+	s, _ := newTestManager(t, WithSlotCount(3))
+	if err := s.SaveSlot(2, `{"day":5}`); err != nil {
+		t.Fatal(err)
+	}
+	slots, err := s.GetSlots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slots) != 3 {
+		t.Fatalf("GetSlots returned %d slots, want 3", len(slots))
+	}
+	for i, slot := range slots {
+		if slot.Index != i+1 {
+			t.Errorf("slot %d has index %d", i+1, slot.Index)
+		}
+		if want := slot.Index == 2; slot.Occupied != want {
+			t.Errorf("slot %d occupied = %v, want %v", slot.Index, slot.Occupied, want)
+		}
+	}
+	got, err := s.LoadSlot(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"day":5}` {
+		t.Errorf("LoadSlot(2) = %s", got)
+	}
+}
+
+func TestSlotOutOfRange(t *testing.T) {
+	s, _ := newTestManager(t, WithSlotCount(3))
+	for _, index := range []int{0, -1, 4} {
+		if err := s.SaveSlot(index, `{}`); !errors.Is(err, ErrInvalidSlot) {
+			t.Errorf("SaveSlot(%d) = %v, want ErrInvalidSlot", index, err)
+		}
+		if _, err := s.LoadSlot(index); !errors.Is(err, ErrInvalidSlot) {
+			t.Errorf("LoadSlot(%d) = %v, want ErrInvalidSlot", index, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store is where a SaveManager keeps its saves. FSStore, the default, keeps
+// them in the data directory; other implementations can keep them anywhere,
+// such as an object store. Store methods receive names that have already
+// been validated.
+type Store interface {
+	Save(ctx context.Context, saveName, saveData string) error
+	Load(ctx context.Context, saveName string) (string, error)
+	List() ([]string, error)
+	Delete(saveName string) error
+	SetLast(saveName string) error
+	GetLast() (string, error)
+}
+
+// SidecarStore is implemented by stores that can also keep the small files
+// that go with a save, such as its metadata, thumbnail and expiry. ext names
+// the kind of file, as in ".meta". LoadSidecar returns an error wrapping
+// os.ErrNotExist if the save has no such file, and Delete must remove a
+// save's sidecars along with it.
+type SidecarStore interface {
+	SaveSidecar(saveName, ext string, data []byte) error
+	LoadSidecar(saveName, ext string) ([]byte, error)
+	DeleteSidecar(saveName, ext string) error
+}
+
+// ErrStoreUnsupported is returned for a feature that the Store set with
+// WithStore cannot provide.
+var ErrStoreUnsupported = errors.New("not supported by the save store")
+
+// existenceChecker is implemented by stores that can tell whether a save
+// exists more cheaply than by listing every save.
+type existenceChecker interface {
+	Exists(saveName string) (bool, error)
+}
+
+// WithStore makes saves, and the last-save marker, go through store instead
+// of the data directory. Metadata, thumbnails and temporary saves need a
+// store that also implements SidecarStore, and fail with ErrStoreUnsupported
+// otherwise. Features that depend on the file layout, such as backups,
+// deltas and checksums, are left to the store.
+func WithStore(store Store) Option {
+	return func(s *SaveManager) {
+		s.store = store
+	}
+}
+
+// FSStore keeps saves as files in a SaveManager's data directory, with the
+// backups, checksums, compression, encryption and migrations configured on
+// it.
+type FSStore struct {
+	m *SaveManager
+}
+
+func (st *FSStore) Save(ctx context.Context, saveName, saveData string) error {
+	mu := st.m.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := st.m.saveGame(ctx, saveName, saveData); err != nil {
+		return err
+	}
+	return st.m.makePermanent(saveName)
+}
+
+func (st *FSStore) Load(ctx context.Context, saveName string) (string, error) {
+	return st.m.loadGame(ctx, saveName)
+}
+
+func (st *FSStore) Exists(saveName string) (bool, error) {
+	_, err := st.m.savePath(saveName)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (st *FSStore) List() ([]string, error) {
+	return st.m.saveNames()
+}
+
+func (st *FSStore) Delete(saveName string) error {
+	return st.m.deleteSave(saveName)
+}
+
+func (st *FSStore) SetLast(saveName string) error {
+	s := st.m
+	mu := s.saveLock(lastSaveFile)
+	mu.Lock()
+	defer mu.Unlock()
+	filename := filepath.Join(s.dir(), lastSaveFile)
+	return s.writeFileAtomic(filename, s.fileMode, func(w io.Writer) error {
+		_, err := io.WriteString(w, saveName)
+		return err
+	})
+}
+
+func (st *FSStore) GetLast() (string, error) {
+	s := st.m
+	mu := s.saveLock(lastSaveFile)
+	mu.RLock()
+	defer mu.RUnlock()
+	filename := filepath.Join(s.dir(), lastSaveFile)
+	data, err := readFile(s.fs, filename)
+	if err != nil {
+		return "", nil
+	}
+	// Tolerate markers written with a trailing newline by other tools.
+	saveName := strings.TrimSpace(string(data))
+	if validateSaveName(saveName) != nil {
+		return "", nil
+	}
+	// The marker may outlive its save, e.g. one deleted by an older version.
+	if _, err := s.savePath(saveName); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	return saveName, nil
+}
+
+// localStore reports whether saves are kept in the data directory.
+func (s *SaveManager) localStore() bool {
+	_, ok := s.store.(*FSStore)
+	return ok
+}
+
+// saveWithSidecar saves saveData and then the sidecar file with extension
+// ext holding data, both under the save's lock.
+func (s *SaveManager) saveWithSidecar(saveName, saveData, ext string, data []byte) error {
+	ctx := context.Background()
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	if s.localStore() {
+		if err := s.saveGame(ctx, saveName, saveData); err != nil {
+			return err
+		}
+		if ext != expiryExt {
+			if err := s.makePermanent(saveName); err != nil {
+				return err
+			}
+		}
+		return s.writeFileAtomic(filepath.Join(s.dir(), saveName+ext), s.fileMode, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+	}
+	sc, ok := s.store.(SidecarStore)
+	if !ok {
+		return fmt.Errorf("save %q with %s: %w", saveName, ext, ErrStoreUnsupported)
+	}
+	if err := s.store.Save(ctx, saveName, saveData); err != nil {
+		return err
+	}
+	if ext != expiryExt {
+		if err := s.makePermanent(saveName); err != nil {
+			return err
+		}
+	}
+	return sc.SaveSidecar(saveName, ext, data)
+}
+
+// loadSidecar returns the sidecar file of saveName with extension ext. A
+// store that cannot keep sidecars has none.
+func (s *SaveManager) loadSidecar(saveName, ext string) ([]byte, error) {
+	if s.localStore() {
+		return readFile(s.fs, filepath.Join(s.dir(), saveName+ext))
+	}
+	sc, ok := s.store.(SidecarStore)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return sc.LoadSidecar(saveName, ext)
+}
+
+// checkSaveExists returns an error wrapping ErrSaveNotFound if saveName does
+// not exist in the store.
+func (s *SaveManager) checkSaveExists(saveName string) error {
+	if s.localStore() {
+		_, err := s.savePath(saveName)
+		return err
+	}
+	exists, err := s.HasSave(saveName)
+	if err == nil && !exists {
+		err = fmt.Errorf("%w: %w", ErrSaveNotFound, os.ErrNotExist)
+	}
+	return err
+}
+
+// storeSave saves saveData through the store and makes the save permanent,
+// which FSStore's Save does itself.
+func (s *SaveManager) storeSave(ctx context.Context, saveName, saveData string) error {
+	if s.localStore() {
+		return s.store.Save(ctx, saveName, saveData)
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := s.store.Save(ctx, saveName, saveData); err != nil {
+		return err
+	}
+	return s.makePermanent(saveName)
+}
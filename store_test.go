@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapStore is a Store that keeps saves and their sidecars in memory.
+type mapStore struct {
+	mu       sync.Mutex
+	saves    map[string]string
+	sidecars map[string][]byte
+	last     string
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{saves: make(map[string]string), sidecars: make(map[string][]byte)}
+}
+
+func (m *mapStore) Save(ctx context.Context, saveName, saveData string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saves[saveName] = saveData
+	return nil
+}
+
+func (m *mapStore) Load(ctx context.Context, saveName string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saveData, ok := m.saves[saveName]
+	if !ok {
+		return "", ErrSaveNotFound
+	}
+	return saveData, nil
+}
+
+func (m *mapStore) List() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Sorted(maps.Keys(m.saves)), nil
+}
+
+func (m *mapStore) Delete(saveName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.saves[saveName]; !ok {
+		return ErrSaveNotFound
+	}
+	delete(m.saves, saveName)
+	for key := range m.sidecars {
+		if strings.HasPrefix(key, saveName+".") {
+			delete(m.sidecars, key)
+		}
+	}
+	return nil
+}
+
+func (m *mapStore) SetLast(saveName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last = saveName
+	return nil
+}
+
+func (m *mapStore) GetLast() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last, nil
+}
+
+func (m *mapStore) SaveSidecar(saveName, ext string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sidecars[saveName+ext] = data
+	return nil
+}
+
+func (m *mapStore) LoadSidecar(saveName, ext string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.sidecars[saveName+ext]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (m *mapStore) DeleteSidecar(saveName, ext string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sidecars, saveName+ext)
+	return nil
+}
+
+// plainStore is a Store that cannot keep sidecars.
+type plainStore struct {
+	Store
+}
+
+func TestWithStoreKeepsEverySaveInStore(t *testing.T) {
+	store := newMapStore()
+	s, dir := newTestManager(t, WithStore(store))
+	if err := s.SaveGameWithMeta("meta", `{"day":1}`, map[string]string{"day": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveGameWithThumbnail("thumb", `{"day":1}`, []byte("png")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveGameReader("stream", strings.NewReader(`{"day":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "delta", `{"day":1}`)
+	if err := s.SaveDelta("delta", `{"day":1}`, `{"day":2}`); err != nil {
+		t.Fatal(err)
+	}
+	err := s.UpdateSave("stream", func(data []byte) ([]byte, error) {
+		return []byte(`{"day":3}`), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names, err := s.GetAllSaves(); err != nil || !slices.Equal(names, []string{"delta", "meta", "stream", "thumb"}) {
+		t.Errorf("GetAllSaves = %v, %v", names, err)
+	}
+	if got := mustLoad(t, s, "delta"); got != `{"day":2}` {
+		t.Errorf("LoadGame after SaveDelta = %s", got)
+	}
+	if meta, err := s.GetSaveMeta("meta"); err != nil || meta["day"] != "1" {
+		t.Errorf("GetSaveMeta = %v, %v", meta, err)
+	}
+	if png, err := s.GetThumbnail("thumb"); err != nil || string(png) != "png" {
+		t.Errorf("GetThumbnail = %q, %v", png, err)
+	}
+	r, err := s.LoadGameReader("stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, err := io.ReadAll(r); err != nil || string(data) != `{"day":3}` {
+		t.Errorf("LoadGameReader read %s, %v", data, err)
+	}
+	if files := dirFiles(t, dir); len(files) != 0 {
+		t.Errorf("data directory holds %v, want everything in the store", files)
+	}
+}
+
+func TestWithStoreTemporarySaves(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newMapStore()
+	s, _ := newTestManager(t, WithStore(store), WithClock(func() time.Time { return now }))
+	if err := s.SaveGameTemporary("debug", `{}`, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	mustSave(t, s, "farm", `{}`)
+	if err := s.SaveGameTemporary("farm", `{}`, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// Saving over a temporary save makes it permanent again.
+	mustSave(t, s, "farm", `{}`)
+	now = now.Add(2 * time.Hour)
+	if n, err := s.PurgeExpired(); err != nil || n != 1 {
+		t.Fatalf("PurgeExpired = %d, %v, want 1", n, err)
+	}
+	if names, err := s.GetAllSaves(); err != nil || !slices.Equal(names, []string{"farm"}) {
+		t.Errorf("GetAllSaves after purge = %v, %v, want [farm]", names, err)
+	}
+	if len(store.sidecars) != 0 {
+		t.Errorf("store sidecars after purge = %v, want none", store.sidecars)
+	}
+}
+
+func TestWithStoreWithoutSidecars(t *testing.T) {
+	store := newMapStore()
+	s, dir := newTestManager(t, WithStore(plainStore{store}))
+	if err := s.SaveGameWithMeta("farm", `{}`, map[string]string{"day": "1"}); !errors.Is(err, ErrStoreUnsupported) {
+		t.Errorf("SaveGameWithMeta = %v, want ErrStoreUnsupported", err)
+	}
+	if err := s.SaveGameWithThumbnail("farm", `{}`, []byte("png")); !errors.Is(err, ErrStoreUnsupported) {
+		t.Errorf("SaveGameWithThumbnail = %v, want ErrStoreUnsupported", err)
+	}
+	if err := s.SaveGameTemporary("farm", `{}`, time.Hour); !errors.Is(err, ErrStoreUnsupported) {
+		t.Errorf("SaveGameTemporary = %v, want ErrStoreUnsupported", err)
+	}
+	if files := dirFiles(t, dir); len(files) != 0 {
+		t.Errorf("data directory holds %v, want nothing written", files)
+	}
+	mustSave(t, s, "farm", `{}`)
+	if meta, err := s.GetSaveMeta("farm"); err != nil || len(meta) != 0 {
+		t.Errorf("GetSaveMeta = %v, %v, want none", meta, err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamLargeSave(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"tiles":[`)
+	for i := 0; b.Len() < 4<<20; i++ {
+		fmt.Fprintf(&b, `{"x":%d,"y":%d,"crop":"parsnip"},`, i%640, i/640)
+	}
+	b.WriteString(`{}]}`)
+	saveData := b.String()
+	want := sha256.Sum256([]byte(saveData))
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"plain", nil},
+		{"compressed", []Option{WithCompression(true)}},
+		{"encrypted", []Option{WithEncryption("secret")}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _ := newTestManager(t, tc.opts...)
+			if err := s.SaveGameReader("farm", strings.NewReader(saveData)); err != nil {
+				t.Fatal(err)
+			}
+			rc, err := s.LoadGameReader("farm")
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := sha256.New()
+			_, err = io.Copy(h, rc)
+			rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+				t.Errorf("streamed save has checksum %x, want %x", got, want)
+			}
+			if got := sha256.Sum256([]byte(mustLoad(t, s, "farm"))); got != want {
+				t.Errorf("LoadGame of a streamed save has checksum %x, want %x", got, want)
+			}
+		})
+	}
+}
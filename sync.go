@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// ErrSyncConflict is returned when a save changed both locally and
+	// remotely since it was last synced.
+	ErrSyncConflict = errors.New("save changed both locally and remotely")
+
+	// ErrNoSyncer is returned by SyncUp and SyncDown when no Syncer is set.
+	ErrNoSyncer = errors.New("no syncer configured")
+)
+
+// remoteSuffix is appended to the name of a save to keep the remote copy
+// when syncing runs into a conflict.
+const remoteSuffix = ".remote"
+
+// Syncer stores copies of saves on a remote service.
+type Syncer interface {
+	// Upload stores data as name, along with localModTime, which Download
+	// must report back as the remote modification time.
+	Upload(name string, data []byte, localModTime time.Time) error
+	// Download returns the stored copy of name and its modification time.
+	// The error wraps os.ErrNotExist if there is no copy.
+	Download(name string) (data []byte, remoteModTime time.Time, err error)
+}
+
+// WithSyncer sets the Syncer used by SyncUp and SyncDown.
+func WithSyncer(syncer Syncer) Option {
+	return func(s *SaveManager) {
+		s.syncer = syncer
+	}
+}
+
+// syncState is what both copies of a save looked like when it was last
+// synced.
+type syncState struct {
+	Local  time.Time `json:"local"`
+	Remote time.Time `json:"remote"`
+}
+
+func (s *SaveManager) syncStatePath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".sync")
+}
+
+// SyncUp uploads saveName unless only the remote copy changed since the last
+// sync. If both copies changed, the remote one is kept as the save
+// saveName+".remote" and ErrSyncConflict is returned.
+func (s *SaveManager) SyncUp(saveName string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	if s.syncer == nil {
+		return ErrNoSyncer
+	}
+	saveData, err := s.loadGame(context.Background(), saveName)
+	if err != nil {
+		return fmt.Errorf("sync up save %q: %w", saveName, err)
+	}
+	localMod, state, err := s.localSyncState(saveName)
+	if err != nil {
+		return fmt.Errorf("sync up save %q: %w", saveName, err)
+	}
+	remote, remoteMod, err := s.syncer.Download(saveName)
+	remoteExists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("sync up save %q: %w", saveName, err)
+	}
+
+	localChanged := !localMod.Equal(state.Local)
+	remoteChanged := remoteExists && !remoteMod.Equal(state.Remote)
+	switch {
+	case localChanged && remoteChanged && string(remote) != saveData:
+		return s.syncConflict(saveName, remote)
+	case localChanged && remoteChanged:
+		return s.recordSync(saveName, syncState{Local: localMod, Remote: remoteMod})
+	case localChanged || !remoteExists:
+		if err := s.syncer.Upload(saveName, []byte(saveData), localMod); err != nil {
+			return fmt.Errorf("sync up save %q: %w", saveName, err)
+		}
+		return s.recordSync(saveName, syncState{Local: localMod, Remote: localMod})
+	}
+	return nil
+}
+
+// SyncDown replaces saveName with its remote copy unless only the local save
+// changed since the last sync. If both copies changed, the remote one is kept
+// as the save saveName+".remote" and ErrSyncConflict is returned.
+func (s *SaveManager) SyncDown(saveName string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	if s.syncer == nil {
+		return ErrNoSyncer
+	}
+	remote, remoteMod, err := s.syncer.Download(saveName)
+	if err != nil {
+		return fmt.Errorf("sync down save %q: %w", saveName, err)
+	}
+	localMod, state, err := s.localSyncState(saveName)
+	localExists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("sync down save %q: %w", saveName, err)
+	}
+
+	localChanged := localExists && !localMod.Equal(state.Local)
+	remoteChanged := !remoteMod.Equal(state.Remote)
+	if localChanged && remoteChanged {
+		saveData, err := s.loadGame(context.Background(), saveName)
+		if err != nil {
+			return fmt.Errorf("sync down save %q: %w", saveName, err)
+		}
+		if saveData != string(remote) {
+			return s.syncConflict(saveName, remote)
+		}
+		return s.recordSync(saveName, syncState{Local: localMod, Remote: remoteMod})
+	}
+	if !remoteChanged && localExists {
+		return nil
+	}
+
+	saveData, err := s.formatSaveData(string(remote))
+	if err != nil {
+		return fmt.Errorf("sync down save %q: %w", saveName, err)
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	err = s.saveGame(context.Background(), saveName, saveData)
+	if err == nil {
+		localMod, err = s.saveModTime(saveName)
+	}
+	if err == nil {
+		err = s.writeSyncState(saveName, syncState{Local: localMod, Remote: remoteMod})
+	}
+	mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("sync down save %q: %w", saveName, err)
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return nil
+}
+
+// syncConflict keeps the remote copy of saveName next to the local one and
+// reports the conflict.
+func (s *SaveManager) syncConflict(saveName string, remote []byte) error {
+	remoteName := saveName + remoteSuffix
+	if err := s.SaveGame(remoteName, string(remote)); err != nil {
+		return fmt.Errorf("sync save %q: keep remote copy: %w", saveName, err)
+	}
+	return fmt.Errorf("sync save %q: %w; remote copy kept as %q", saveName, ErrSyncConflict, remoteName)
+}
+
+// localSyncState returns the modification time of saveName and the state
+// recorded by its last sync, which is zero if it was never synced.
+func (s *SaveManager) localSyncState(saveName string) (time.Time, syncState, error) {
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	var state syncState
+	localMod, err := s.saveModTime(saveName)
+	if err != nil {
+		return localMod, state, err
+	}
+	data, err := readFile(s.fs, s.syncStatePath(saveName))
+	if errors.Is(err, os.ErrNotExist) {
+		return localMod, state, nil
+	}
+	if err != nil {
+		return localMod, state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return localMod, state, err
+}
+
+func (s *SaveManager) saveModTime(saveName string) (time.Time, error) {
+	filename, err := s.savePath(saveName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	fi, err := s.fs.Stat(filename)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
+
+// recordSync is writeSyncState under the save's lock.
+func (s *SaveManager) recordSync(saveName string, state syncState) error {
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	return s.writeSyncState(saveName, state)
+}
+
+// writeSyncState records state as the last sync of saveName. The
+// caller must hold the save's lock.
+func (s *SaveManager) writeSyncState(saveName string, state syncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.writeFileAtomic(s.syncStatePath(saveName), s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// stubSyncer keeps uploaded saves in memory.
+type stubSyncer struct {
+	data map[string][]byte
+	mod  map[string]time.Time
+}
+
+func newStubSyncer() *stubSyncer {
+	return &stubSyncer{data: make(map[string][]byte), mod: make(map[string]time.Time)}
+}
+
+func (st *stubSyncer) Upload(name string, data []byte, localModTime time.Time) error {
+	st.data[name], st.mod[name] = data, localModTime
+	return nil
+}
+
+func (st *stubSyncer) Download(name string) ([]byte, time.Time, error) {
+	data, ok := st.data[name]
+	if !ok {
+		return nil, time.Time{}, os.ErrNotExist
+	}
+	return data, st.mod[name], nil
+}
+
+func TestSyncUpAndDown(t *testing.T) {
+	remote := newStubSyncer()
+	laptop, _ := newTestManager(t, WithSyncer(remote))
+	desktop, _ := newTestManager(t, WithSyncer(remote))
+
+	mustSave(t, laptop, "farm", `{"day":1}`)
+	if err := laptop.SyncUp("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if string(remote.data["farm"]) != `{"day":1}` {
+		t.Errorf("uploaded %s", remote.data["farm"])
+	}
+	if err := desktop.SyncDown("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, desktop, "farm"); got != `{"day":1}` {
+		t.Errorf("LoadGame after SyncDown = %s", got)
+	}
+
+	// Only the remote copy changes, so the laptop's SyncUp keeps it and
+	// SyncDown fetches it.
+	mustSave(t, desktop, "farm", `{"day":2}`)
+	if err := desktop.SyncUp("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := laptop.SyncUp("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if string(remote.data["farm"]) != `{"day":2}` {
+		t.Errorf("SyncUp of an unchanged save replaced the remote copy with %s", remote.data["farm"])
+	}
+	if err := laptop.SyncDown("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustLoad(t, laptop, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame after SyncDown = %s", got)
+	}
+}
+
+func TestSyncConflict(t *testing.T) {
+	remote := newStubSyncer()
+	laptop, _ := newTestManager(t, WithSyncer(remote))
+	desktop, _ := newTestManager(t, WithSyncer(remote))
+	mustSave(t, laptop, "farm", `{"day":1}`)
+	if err := laptop.SyncUp("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := desktop.SyncDown("farm"); err != nil {
+		t.Fatal(err)
+	}
+
+	mustSave(t, laptop, "farm", `{"day":2}`)
+	mustSave(t, desktop, "farm", `{"day":3}`)
+	if err := desktop.SyncUp("farm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := laptop.SyncUp("farm"); !errors.Is(err, ErrSyncConflict) {
+		t.Fatalf("SyncUp = %v, want ErrSyncConflict", err)
+	}
+	if got := mustLoad(t, laptop, "farm"); got != `{"day":2}` {
+		t.Errorf("local save changed to %s", got)
+	}
+	if got := mustLoad(t, laptop, "farm"+remoteSuffix); got != `{"day":3}` {
+		t.Errorf("remote copy kept as %s", got)
+	}
+	if string(remote.data["farm"]) != `{"day":3}` {
+		t.Errorf("conflicting SyncUp replaced the remote copy with %s", remote.data["farm"])
+	}
+	if err := laptop.SyncDown("farm"); !errors.Is(err, ErrSyncConflict) {
+		t.Errorf("SyncDown = %v, want ErrSyncConflict", err)
+	}
+}
+
+func TestSyncWithoutSyncer(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.SyncUp("farm"); !errors.Is(err, ErrNoSyncer) {
+		t.Errorf("SyncUp = %v, want ErrNoSyncer", err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func (s *SaveManager) tagsPath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".tags")
+}
+
+// TagSave replaces the tags of saveName, such as "creative" or "main", which
+// list screens use to group saves. Tags are trimmed and duplicates dropped;
+// an empty list removes all tags.
+func (s *SaveManager) TagSave(saveName string, tags []string) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := s.savePath(saveName); err != nil {
+		return fmt.Errorf("tag save %q: %w", saveName, err)
+	}
+	cleaned := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(cleaned, tag) {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	if len(cleaned) == 0 {
+		if err := s.fs.Remove(s.tagsPath(saveName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.Marshal(cleaned)
+	if err != nil {
+		return err
+	}
+	return s.writeFileAtomic(s.tagsPath(saveName), s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// GetSavesByTag returns the saves tagged with tag, ignoring case, most
+// recently modified first.
+func (s *SaveManager) GetSavesByTag(tag string) ([]SaveInfo, error) {
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		return nil, err
+	}
+	tag = strings.TrimSpace(tag)
+	found := []SaveInfo{}
+	for _, info := range infos {
+		if slices.ContainsFunc(info.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			found = append(found, info)
+		}
+	}
+	return found, nil
+}
+
+// readTags returns the tags stored with saveName.
+func (s *SaveManager) readTags(saveName string) ([]string, error) {
+	tags := []string{}
+	data, err := readFile(s.fs, s.tagsPath(saveName))
+	if errors.Is(err, os.ErrNotExist) {
+		return tags, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// saveNamesOf returns the names of infos, sorted.
+func saveNamesOf(infos []SaveInfo) []string {
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestGetSavesByTag(t *testing.T) {
+	s, _ := newTestManager(t)
+	for _, saveName := range []string{"sandbox", "island", "farm"} {
+		mustSave(t, s, saveName, `{}`)
+	}
+	if err := s.TagSave("sandbox", []string{"creative"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.TagSave("island", []string{" Creative ", "beach"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.TagSave("farm", []string{"main"}); err != nil {
+		t.Fatal(err)
+	}
+
+	creative, err := s.GetSavesByTag("creative")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saveNamesOf(creative); !slices.Equal(got, []string{"island", "sandbox"}) {
+		t.Errorf("GetSavesByTag(creative) = %v", got)
+	}
+	main, err := s.GetSavesByTag("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(main) != 1 || main[0].Name != "farm" || !slices.Equal(main[0].Tags, []string{"main"}) {
+		t.Errorf("GetSavesByTag(main) = %+v", main)
+	}
+	infos, err := s.GetAllSaveInfos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if len(info.Tags) == 0 {
+			t.Errorf("GetAllSaveInfos has no tags for %s", info.Name)
+		}
+	}
+	if err := s.TagSave("missing", []string{"main"}); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("TagSave(missing) = %v, want ErrSaveNotFound", err)
+	}
+}
+
+func TestTagsFollowTheirSave(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{}`)
+	if err := s.TagSave("farm", []string{"main"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RenameSave("farm", "valley"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DuplicateSave("valley", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	main, err := s.GetSavesByTag("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saveNamesOf(main); !slices.Equal(got, []string{"copy", "valley"}) {
+		t.Errorf("GetSavesByTag after rename and duplicate = %v", got)
+	}
+	if err := s.DeleteSave("valley"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "valley.tags")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("tags not deleted with their save: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func (s *SaveManager) thumbnailPath(saveName string) string {
+	return filepath.Join(s.dir(), saveName+".png")
+}
+
+// SaveGameWithThumbnail saves saveData like SaveGame and stores thumbnail, a
+// PNG image, next to it for the load screen.
+func (s *SaveManager) SaveGameWithThumbnail(saveName, saveData string, thumbnail []byte) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	saveData, err := s.formatSaveData(saveData)
+	if err != nil {
+		return fmt.Errorf("save %q: %w", saveName, err)
+	}
+	if err := s.saveWithSidecar(saveName, saveData, ".png", thumbnail); err != nil {
+		return err
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return nil
+}
+
+// GetThumbnail returns the PNG stored with saveName, or nil if it has none.
+func (s *SaveManager) GetThumbnail(saveName string) ([]byte, error) {
+	if err := validateSaveName(saveName); err != nil {
+		return nil, err
+	}
+	mu := s.saveLock(saveName)
+	mu.RLock()
+	defer mu.RUnlock()
+	if err := s.checkSaveExists(saveName); err != nil {
+		return nil, fmt.Errorf("get thumbnail of save %q: %w", saveName, err)
+	}
+	data, err := s.loadSidecar(saveName, ".png")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// UpdateSave loads saveName, passes its data to fn and saves what fn returns
+// in its place, all under the save's write lock so that no other write can
+// slip in between. The result must be JSON, as for SaveGame. If fn returns an
+// error, the save is left untouched and the error is returned.
+func (s *SaveManager) UpdateSave(saveName string, fn func(data []byte) ([]byte, error)) error {
+	if err := validateSaveName(saveName); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	load, save := s.loadGameLocked, s.saveGame
+	if !s.localStore() {
+		// Other stores do no locking of their own.
+		load, save = s.store.Load, s.store.Save
+	}
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	saveData, err := load(ctx, saveName)
+	if err == nil {
+		var updated []byte
+		updated, err = fn([]byte(saveData))
+		if err == nil {
+			saveData, err = s.formatSaveData(string(updated))
+		}
+		if err == nil {
+			err = save(ctx, saveName, saveData)
+		}
+	}
+	mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("update save %q: %w", saveName, err)
+	}
+	s.notifySave(saveName, int64(len(saveData)))
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// incrementDay adds one to the day of a save.
+func incrementDay(data []byte) ([]byte, error) {
+	var save struct {
+		Day int `json:"day"`
+	}
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+	save.Day++
+	return json.Marshal(save)
+}
+
+func TestUpdateSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":0}`)
+	for i := 0; i < 2; i++ {
+		if err := s.UpdateSave("farm", incrementDay); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":2}` {
+		t.Errorf("LoadGame = %s, want both increments", got)
+	}
+}
+
+func TestUpdateSaveConcurrent(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":0}`)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.UpdateSave("farm", incrementDay); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := mustLoad(t, s, "farm"); got != `{"day":20}` {
+		t.Errorf("LoadGame = %s, want every increment", got)
+	}
+}
+
+func TestUpdateSaveFailureLeavesSave(t *testing.T) {
+	s, _ := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	failed := errors.New("update failed")
+	if err := s.UpdateSave("farm", func([]byte) ([]byte, error) { return nil, failed }); !errors.Is(err, failed) {
+		t.Errorf("UpdateSave = %v, want the callback's error", err)
+	}
+	if err := s.UpdateSave("farm", func([]byte) ([]byte, error) { return []byte(`{"day":`), nil }); !errors.Is(err, ErrInvalidSaveData) {
+		t.Errorf("UpdateSave returning bad JSON = %v, want ErrInvalidSaveData", err)
+	}
+	if got := mustLoad(t, s, "farm"); got != `{"day":1}` {
+		t.Errorf("failed updates changed the save to %s", got)
+	}
+	if err := s.UpdateSave("missing", incrementDay); !errors.Is(err, ErrSaveNotFound) {
+		t.Errorf("UpdateSave(missing) = %v, want ErrSaveNotFound", err)
+	}
+}
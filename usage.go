@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DiskUsage returns the total size in bytes of all saves, including their
+// sidecars, backups, deltas and bundles.
+func (s *SaveManager) DiskUsage() (int64, error) {
+	usage, err := s.DiskUsageBySave()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, size := range usage {
+		total += size
+	}
+	return total, nil
+}
+
+// DiskUsageBySave returns the size in bytes of each save, including its
+// sidecars, backups, deltas and bundles, which count as saves of their own
+// when saved with SaveBundle alone. Files left behind by deleted saves are
+// not counted.
+func (s *SaveManager) DiskUsageBySave() (map[string]int64, error) {
+	files, err := s.fs.ReadDir(s.dir())
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]int64)
+	saves := make(map[string]bool)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		name, ok := s.trimSaveExt(file.Name())
+		if ok {
+			saves[name] = true
+		} else if name, ok = trimSidecarExt(file.Name()); !ok {
+			continue
+		}
+		fi, err := file.Info()
+		if err != nil {
+			continue
+		}
+		usage[name] += fi.Size()
+	}
+
+	for _, root := range []string{"backups", "bundles", "deltas"} {
+		dirs, err := s.fs.ReadDir(filepath.Join(s.dir(), root))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			if !dir.IsDir() {
+				continue
+			}
+			if root == "bundles" {
+				saves[dir.Name()] = true
+			}
+			files, err := s.fs.ReadDir(filepath.Join(s.dir(), root, dir.Name()))
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				if fi, err := file.Info(); err == nil && !file.IsDir() {
+					usage[dir.Name()] += fi.Size()
+				}
+			}
+		}
+	}
+
+	for name := range usage {
+		if !saves[name] {
+			delete(usage, name)
+		}
+	}
+	return usage, nil
+}
+
+// trimSidecarExt strips a sidecar extension from filename, reporting false if
+// filename is not a sidecar.
+func trimSidecarExt(filename string) (string, bool) {
+	for _, ext := range sidecarExts {
+		if strings.HasSuffix(filename, ext) {
+			return strings.TrimSuffix(filename, ext), true
+		}
+	}
+	return "", false
+}
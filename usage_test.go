@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiskUsage(t *testing.T) {
+	s, dir := newTestManager(t)
+	mustSave(t, s, "farm", `{"day":1}`)
+	mustSave(t, s, "farm", `{"day":2,"gold":100}`)
+	if err := s.SaveGameWithThumbnail("mine", `{"depth":40}`, []byte("png data")); err != nil {
+		t.Fatal(err)
+	}
+	// Left behind by a deleted save.
+	if err := os.WriteFile(filepath.Join(dir, "gone.png"), []byte("orphan"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int64{}
+	for _, name := range dirFiles(t, dir) {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case strings.HasPrefix(name, "farm.") || strings.HasPrefix(name, "backups/farm/"):
+			want["farm"] += fi.Size()
+		case strings.HasPrefix(name, "mine."):
+			want["mine"] += fi.Size()
+		}
+	}
+	if want["farm"] <= int64(len(`{"day":2,"gold":100}`)+len(`{"day":1}`)) {
+		t.Fatalf("farm files total %d bytes, expected the save, its backup and sidecars", want["farm"])
+	}
+
+	usage, err := s.DiskUsageBySave()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != len(want) || usage["farm"] != want["farm"] || usage["mine"] != want["mine"] {
+		t.Errorf("DiskUsageBySave = %v, want %v", usage, want)
+	}
+	total, err := s.DiskUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != want["farm"]+want["mine"] {
+		t.Errorf("DiskUsage = %d, want %d", total, want["farm"]+want["mine"])
+	}
+}
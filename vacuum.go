@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// staleTempAge is how old a temporary file must be before Vacuum takes it
+// for the remains of an interrupted write rather than one in progress.
+const staleTempAge = time.Minute
+
+// VacuumOptions selects the optional work done by Vacuum.
+type VacuumOptions struct {
+	// Compress rewrites the saves stored uncompressed in compressed form,
+	// where that makes them smaller.
+	Compress bool `json:"compress"`
+}
+
+// VacuumReport says what Vacuum reclaimed.
+type VacuumReport struct {
+	FilesRemoved    int   `json:"filesRemoved"`
+	BytesReclaimed  int64 `json:"bytesReclaimed"`
+	SavesCompressed int   `json:"savesCompressed"`
+}
+
+func (r *VacuumReport) add(files int, size int64) {
+	r.FilesRemoved += files
+	r.BytesReclaimed += size
+}
+
+// Vacuum tidies up the data directory. It prunes backups beyond the
+// WithMaxBackups limit, removes the sidecars, backups, deltas and unheld lock
+// files of saves that no longer exist, removes temporary files left by
+// writes interrupted more than a minute ago, and finishes or undoes
+// interrupted SaveBundle calls. Bundles are kept, as they can be saves of
+// their own. Live saves and their sidecars
+// are never removed. The report covers the work done so far even if an error
+// stops Vacuum part way.
+func (s *SaveManager) Vacuum(opts VacuumOptions) (VacuumReport, error) {
+	var report VacuumReport
+	removed, size, err := s.removeTempFiles(s.now().Add(-staleTempAge))
+	report.add(removed, size)
+	if err != nil {
+		return report, err
+	}
+	removed, size, err = s.recoverBundles()
+	report.add(removed, size)
+	if err != nil {
+		return report, err
+	}
+
+	files, err := s.fs.ReadDir(s.dir())
+	if err != nil {
+		return report, err
+	}
+	saves := make(map[string]bool)
+	orphans := make(map[string]bool)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if name, ok := s.trimSaveExt(file.Name()); ok {
+			saves[name] = true
+		} else if name, ok := trimSidecarExt(file.Name()); ok {
+			orphans[name] = true
+		} else if name, ok := strings.CutSuffix(file.Name(), ".lock"); ok {
+			orphans[name] = true
+		}
+	}
+	for _, root := range []string{"backups", "deltas"} {
+		dirs, err := s.fs.ReadDir(filepath.Join(s.dir(), root))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return report, err
+		}
+		for _, dir := range dirs {
+			if dir.IsDir() {
+				orphans[dir.Name()] = true
+			}
+		}
+	}
+	for name := range saves {
+		delete(orphans, name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(orphans)) {
+		if validateSaveName(name) != nil {
+			continue
+		}
+		if err := s.removeOrphan(name, &report); err != nil {
+			return report, err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(saves)) {
+		if err := s.vacuumSave(name, opts, &report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// removeOrphan removes everything left behind by saveName, unless the save
+// has been written again in the meantime.
+func (s *SaveManager) removeOrphan(saveName string, report *VacuumReport) error {
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := s.savePath(saveName); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, ext := range sidecarExts {
+		if err := s.removeCounted(filepath.Join(s.dir(), saveName+ext), report); err != nil {
+			return err
+		}
+	}
+	for _, dir := range []string{s.backupDir(saveName), s.deltaDir(saveName)} {
+		files, err := s.fs.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if !file.IsDir() {
+				if err := s.removeCounted(filepath.Join(dir, file.Name()), report); err != nil {
+					return err
+				}
+			}
+		}
+		if err := s.fs.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	return s.removeLockFile(saveName, report)
+}
+
+// removeLockFile removes the lock file of the deleted save saveName if no
+// process holds it.
+func (s *SaveManager) removeLockFile(saveName string, report *VacuumReport) error {
+	s.fileLocksMu.Lock()
+	defer s.fileLocksMu.Unlock()
+	if _, ok := s.fileLocks[saveName]; ok {
+		return nil
+	}
+	filename := s.lockFilePath(saveName)
+	fi, err := os.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	f, err := s.acquireFileLock(saveName)
+	if errors.Is(err, ErrSaveLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := releaseFileLock(f); err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	report.add(1, fi.Size())
+	return nil
+}
+
+// vacuumSave prunes the backups of the live save saveName and compresses it
+// if opts asks for that.
+func (s *SaveManager) vacuumSave(saveName string, opts VacuumOptions, report *VacuumReport) error {
+	mu := s.saveLock(saveName)
+	mu.Lock()
+	defer mu.Unlock()
+	filename, err := s.savePath(saveName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ids, err := s.backupIDs(saveName)
+	if err != nil {
+		return err
+	}
+	dir := s.backupDir(saveName)
+	for len(ids) > max(s.maxBackups, 0) {
+		for _, ext := range append(slices.Clone(s.fileExts), ".header") {
+			if err := s.removeCounted(filepath.Join(dir, ids[0]+ext), report); err != nil {
+				return err
+			}
+		}
+		ids = ids[1:]
+	}
+	if opts.Compress && !strings.HasSuffix(filename, gzExt) {
+		err := s.compressSave(saveName, filename, report)
+		if errors.Is(err, ErrSaveLocked) {
+			// Another instance is using the save; leave it for next time.
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// compressSave rewrites the uncompressed save file filename of saveName in
+// compressed form. Saves that fail their checksum or cannot be decrypted are
+// left alone. It fails with ErrSaveLocked if another process holds the save's
+// OS lock. The caller must hold the save's write lock.
+func (s *SaveManager) compressSave(saveName, filename string, report *VacuumReport) error {
+	release, err := s.holdFileLock(saveName)
+	if err != nil {
+		return err
+	}
+	defer release()
+	data, err := readFile(s.fs, filename)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || s.verifyChecksum(saveName, data) != nil {
+		return nil
+	}
+	r, err := s.decodeFile(filename, data)
+	if err != nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, r); err != nil {
+		return nil
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	compressed := buf.Bytes()
+	if isEncrypted(data) {
+		if compressed, err = s.encrypt(compressed); err != nil {
+			return err
+		}
+	}
+	if len(compressed) >= len(data) {
+		return nil
+	}
+
+	err = s.writeFileAtomic(filename+gzExt, s.fileMode, func(w io.Writer) error {
+		_, err := w.Write(compressed)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(compressed)
+	if err := s.writeChecksum(saveName, sum[:]); err != nil {
+		return err
+	}
+	if err := s.fs.Remove(filename); err != nil {
+		return err
+	}
+	if err := s.bumpGeneration(saveName); err != nil {
+		return err
+	}
+	report.BytesReclaimed += int64(len(data) - len(compressed))
+	report.SavesCompressed++
+	return nil
+}
+
+// removeCounted removes filename, if it exists, and adds it to report.
+func (s *SaveManager) removeCounted(filename string, report *VacuumReport) error {
+	fi, err := s.fs.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.fs.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	report.add(1, fi.Size())
+	return nil
+}
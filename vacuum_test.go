@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVacuumRemovesOnlyJunk(t *testing.T) {
+	s, dir := newTestManager(t, WithMaxBackups(5))
+	for day := 1; day <= 5; day++ {
+		mustSave(t, s, "farm", `{"day":`+strings.Repeat("1", day)+`}`)
+	}
+	if err := s.SaveGameWithMeta("farm", `{"day":6}`, map[string]string{"season": "spring"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.TagSave("farm", []string{"main"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LockSave("held"); err != nil {
+		t.Fatal(err)
+	}
+	defer s.UnlockSave("held")
+
+	junk := []string{
+		"gone.meta",
+		"gone.png",
+		"gone.sha256",
+		"gone.lock",
+		"stale.json" + tmpExt,
+		"backups/gone/20200101-000000.000000000.json",
+		"deltas/gone/000001.json",
+	}
+	for _, name := range junk {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("junk"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hourAgo := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "stale.json"+tmpExt), hourAgo, hourAgo); err != nil {
+		t.Fatal(err)
+	}
+	// Possibly still being written by a running save.
+	if err := os.WriteFile(filepath.Join(dir, "fresh.json"+tmpExt), []byte("junk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := make(map[string]int64)
+	before := dirFiles(t, dir)
+	for _, name := range before {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[name] = fi.Size()
+	}
+
+	// Reopened with a tighter backup limit.
+	s, err := NewSaveManagerWithDir(dir, WithMaxBackups(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.Vacuum(VacuumOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := dirFiles(t, dir)
+	var removed []string
+	var reclaimed int64
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			removed = append(removed, name)
+			reclaimed += sizes[name]
+		}
+	}
+	for _, name := range removed {
+		if !slices.Contains(junk, name) && !strings.HasPrefix(name, "backups/farm/") {
+			t.Errorf("Vacuum removed %s", name)
+		}
+	}
+	for _, name := range junk {
+		if slices.Contains(after, name) {
+			t.Errorf("Vacuum left %s", name)
+		}
+	}
+	for _, name := range []string{"farm.json", "farm.meta", "farm.tags", "farm.header", "farm.sha256", "held.lock", "fresh.json" + tmpExt} {
+		if !slices.Contains(after, name) {
+			t.Errorf("Vacuum removed %s", name)
+		}
+	}
+	if report.FilesRemoved != len(removed) || report.BytesReclaimed != reclaimed {
+		t.Errorf("report = %+v, want %d files and %d bytes", report, len(removed), reclaimed)
+	}
+	if ids, err := s.ListBackups("farm"); err != nil || len(ids) != 2 {
+		t.Errorf("ListBackups = %v, %v, want 2 kept", ids, err)
+	}
+	if err := s.VerifySave("farm"); err != nil {
+		t.Errorf("VerifySave after Vacuum: %v", err)
+	}
+}
+
+func TestVacuumCompresses(t *testing.T) {
+	s, dir := newTestManager(t, WithCache(4))
+	saveData := `{"notes":"` + strings.Repeat("x", 4000) + `"}`
+	mustSave(t, s, "farm", saveData)
+	// Cached before compressing, so a stale cache would still read farm.json.
+	mustLoad(t, s, "farm")
+
+	report, err := s.Vacuum(VacuumOptions{Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SavesCompressed != 1 {
+		t.Errorf("SavesCompressed = %d, want 1", report.SavesCompressed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "farm.json.gz")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "farm.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("uncompressed save left behind: %v", err)
+	}
+	if got := mustLoad(t, s, "farm"); got != saveData {
+		t.Error("LoadGame of the compressed save differs")
+	}
+	if err := s.VerifySave("farm"); err != nil {
+		t.Errorf("VerifySave after compressing: %v", err)
+	}
+}
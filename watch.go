@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// SaveOp describes what happened to a save in a SaveEvent.
+type SaveOp string
+
+const (
+	SaveCreated  SaveOp = "created"
+	SaveModified SaveOp = "modified"
+	SaveDeleted  SaveOp = "deleted"
+)
+
+// SaveEvent reports a change to a save in the data directory.
+type SaveEvent struct {
+	Name string `json:"name"`
+	Op   SaveOp `json:"op"`
+}
+
+// WatchSaves reports saves created, modified or deleted in the data
+// directory, including changes made outside the game. Sidecar, backup and
+// temporary files are not reported. The channel is closed once stop is
+// called; stop is safe to call more than once. Only the real file system can
+// be watched.
+func (s *SaveManager) WatchSaves() (<-chan SaveEvent, func(), error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := watcher.Add(s.dir()); err != nil {
+		watcher.Close()
+		return nil, nil, err
+	}
+	// Saves are replaced by renaming over them, which looks like a create,
+	// so remember which saves exist to tell new saves from updated ones.
+	names, err := s.saveNames()
+	if err != nil {
+		watcher.Close()
+		return nil, nil, err
+	}
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	events := make(chan SaveEvent, 16)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer close(events)
+		for {
+			select {
+			case <-done:
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.reportError(fmt.Errorf("watch saves: %w", err))
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				event, ok := s.saveEvent(ev, known)
+				if !ok {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			watcher.Close()
+			<-finished
+		})
+	}
+	return events, stop, nil
+}
+
+// saveEvent translates a file system event into a SaveEvent, updating known.
+// It reports false for events that aren't about a save.
+func (s *SaveManager) saveEvent(ev fsnotify.Event, known map[string]bool) (SaveEvent, bool) {
+	base := filepath.Base(ev.Name)
+	if strings.HasPrefix(base, ".") {
+		return SaveEvent{}, false
+	}
+	name, ok := s.trimSaveExt(base)
+	if !ok || name == "" {
+		return SaveEvent{}, false
+	}
+	switch {
+	case ev.Has(fsnotify.Create), ev.Has(fsnotify.Write):
+		if known[name] {
+			return SaveEvent{Name: name, Op: SaveModified}, true
+		}
+		known[name] = true
+		return SaveEvent{Name: name, Op: SaveCreated}, true
+	case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+		// Switching compression removes the old variant of a save that
+		// still exists.
+		if _, err := s.savePath(name); err == nil || !known[name] {
+			return SaveEvent{}, false
+		}
+		delete(known, name)
+		return SaveEvent{Name: name, Op: SaveDeleted}, true
+	}
+	return SaveEvent{}, false
+}
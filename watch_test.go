@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchSaves(t *testing.T) {
+	s, dir := newTestManager(t)
+	events, stop, err := s.WatchSaves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// Written by hand, as an external tool would, next to a sidecar and a
+	// temporary file that must not be reported.
+	for _, name := range []string{"farm.json", "farm.sha256", "farm.json" + tmpExt} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(`{}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, "farm.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []SaveEvent
+	timeout := time.After(5 * time.Second)
+	for len(got) == 0 || got[len(got)-1].Op != SaveDeleted {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("no delete event; got %v", got)
+		}
+	}
+	if got[0] != (SaveEvent{Name: "farm", Op: SaveCreated}) {
+		t.Errorf("first event = %v, want farm created", got[0])
+	}
+	for _, ev := range got {
+		if ev.Name != "farm" {
+			t.Errorf("unexpected event %v", ev)
+		}
+	}
+
+	stop()
+	stop()
+	// Drains until the channel is closed.
+	for range events {
+	}
+}